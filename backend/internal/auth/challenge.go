@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Şifre doğrulandıktan sonra 2FA kodunun girilmesi için süre
+	LoginChallengeTTL = 5 * time.Minute
+	// Bu kadar yanlış koddan sonra challenge iptal olur, tekrar şifre girilmeli
+	maxChallengeAttempts = 5
+)
+
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+func challengeKey(token string) string {
+	return fmt.Sprintf("login_challenge_%s", hashToken(token))
+}
+
+// NewLoginChallenge şifresi doğrulanmış, 2FA kodu bekleyen giriş için kısa ömürlü token üretir
+func NewLoginChallenge(ctx context.Context, rdb *redis.Client, userID uint) (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, challengeKey(token), "user_id", userID, "attempts", 0)
+	pipe.Expire(ctx, challengeKey(token), LoginChallengeTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// LoginChallengeUser challenge'ın sahibini döner, challenge silinmez
+func LoginChallengeUser(ctx context.Context, rdb *redis.Client, token string) (uint, error) {
+	if token == "" {
+		return 0, ErrInvalidToken
+	}
+	value, err := rdb.HGet(ctx, challengeKey(token), "user_id").Result()
+	if err == redis.Nil {
+		return 0, ErrInvalidToken
+	}
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return uint(userID), nil
+}
+
+// reserveChallengeScript challenge varsa deneme sayısını artırır; süresi dolmuş challenge yeniden açılmaz
+var reserveChallengeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return -1 end
+return redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+`)
+
+// ReserveChallengeAttempt kod kontrol edilmeden önce denemeyi sayar; aynı anda gelen istekler
+// limiti aşamaz. Limit aşıldıysa challenge silinir ve ErrTooManyAttempts döner.
+func ReserveChallengeAttempt(ctx context.Context, rdb *redis.Client, token string) error {
+	attempts, err := reserveChallengeScript.Run(ctx, rdb, []string{challengeKey(token)}).Int64()
+	if err != nil {
+		return err
+	}
+	if attempts < 0 {
+		return ErrInvalidToken
+	}
+	if attempts > maxChallengeAttempts {
+		rdb.Del(ctx, challengeKey(token))
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// FailLoginChallenge yanlış koddan sonra çağrılır; son deneme de yanlışsa challenge'ı siler
+func FailLoginChallenge(ctx context.Context, rdb *redis.Client, token string) error {
+	attempts, err := rdb.HGet(ctx, challengeKey(token), "attempts").Int64()
+	if err == redis.Nil {
+		return ErrTooManyAttempts
+	}
+	if err != nil {
+		return err
+	}
+	if attempts >= maxChallengeAttempts {
+		rdb.Del(ctx, challengeKey(token))
+		return ErrTooManyAttempts
+	}
+	return nil
+}
+
+// ConsumeLoginChallenge doğru koddan sonra challenge'ı siler; aynı anda iki istekten sadece biri geçer
+func ConsumeLoginChallenge(ctx context.Context, rdb *redis.Client, token string) (bool, error) {
+	n, err := rdb.Del(ctx, challengeKey(token)).Result()
+	return n == 1, err
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestReserveChallengeAttempt(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	token, err := NewLoginChallenge(ctx, rdb, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deneme kod kontrolünden önce sayılır; sonuç beklenmeden limit kadar deneme ayrılabilir
+	for i := 1; i <= maxChallengeAttempts; i++ {
+		if err := ReserveChallengeAttempt(ctx, rdb, token); err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+	if err := ReserveChallengeAttempt(ctx, rdb, token); err != ErrTooManyAttempts {
+		t.Fatalf("attempt over limit: err = %v, want ErrTooManyAttempts", err)
+	}
+	if _, err := LoginChallengeUser(ctx, rdb, token); err != ErrInvalidToken {
+		t.Errorf("challenge not deleted after limit: err = %v", err)
+	}
+	// Silinen challenge sayaçla yeniden açılmaz
+	if err := ReserveChallengeAttempt(ctx, rdb, token); err != ErrInvalidToken {
+		t.Errorf("deleted challenge: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestFailLoginChallenge(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	token, err := NewLoginChallenge(ctx, rdb, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < maxChallengeAttempts; i++ {
+		if err := ReserveChallengeAttempt(ctx, rdb, token); err != nil {
+			t.Fatal(err)
+		}
+		if err := FailLoginChallenge(ctx, rdb, token); err != nil {
+			t.Fatalf("wrong code %d: %v", i, err)
+		}
+	}
+
+	// Son hak da yanlışsa challenge silinir
+	if err := ReserveChallengeAttempt(ctx, rdb, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := FailLoginChallenge(ctx, rdb, token); err != ErrTooManyAttempts {
+		t.Fatalf("last wrong code: err = %v, want ErrTooManyAttempts", err)
+	}
+	if _, err := LoginChallengeUser(ctx, rdb, token); err != ErrInvalidToken {
+		t.Errorf("challenge not deleted: err = %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Başarısız denemeler bu süre boyunca sayılır
+	LoginFailureWindow = 15 * time.Minute
+	// Hesap ve IP bu kadar başarısız denemeden sonra LockoutDuration boyunca kilitlenir
+	AccountLockThreshold = 10
+	IPLockThreshold      = 50
+	LockoutDuration      = 15 * time.Minute
+	// Bu sayıdan sonra her başarısız denemede bekleme süresi iki katına çıkar (1 sn, 2 sn, 4 sn ... en fazla 30 sn)
+	loginDelayAfter = 3
+	maxLoginDelay   = 30 * time.Second
+)
+
+// LoginFailure başarısız denemenin sonucu; kilitlenme olduysa security event yazılmalı
+type LoginFailure struct {
+	AccountLocked bool
+	IPLocked      bool
+}
+
+// loginKeys hesap (e-posta) ve IP için sayaç, bekleme ve kilit key'leri.
+// Hesap e-postayla anahtarlanır, böylece olmayan hesaplar için de aynı cevap döner.
+type loginKeys struct {
+	fails, delay, lock string
+}
+
+func accountLoginKeys(email string) loginKeys {
+	id := hashToken(strings.ToLower(strings.TrimSpace(email)))
+	return loginKeys{
+		fails: fmt.Sprintf("login_fails_account_%s", id),
+		delay: fmt.Sprintf("login_delay_account_%s", id),
+		lock:  fmt.Sprintf("login_lock_account_%s", id),
+	}
+}
+
+func ipLoginKeys(ip string) loginKeys {
+	return loginKeys{
+		fails: fmt.Sprintf("login_fails_ip_%s", ip),
+		delay: fmt.Sprintf("login_delay_ip_%s", ip),
+		lock:  fmt.Sprintf("login_lock_ip_%s", ip),
+	}
+}
+
+// loginDelay n. başarısız denemeden sonra beklenmesi gereken süre
+func loginDelay(fails int64) time.Duration {
+	if fails < loginDelayAfter {
+		return 0
+	}
+	delay := time.Second << min(fails-loginDelayAfter, 5)
+	return min(delay, maxLoginDelay)
+}
+
+// reserveLoginScript kilit ve bekleme süresini kontrol edip hesap ve IP sayaçlarını tek adımda artırır.
+// Deneme şifre kontrolünden önce sayılır; böylece aynı anda gelen istekler eşiği aşamaz.
+// Dönüş: {kilitli mi, kalan süre ms}; süre 0 ise deneme ayrılmıştır.
+var reserveLoginScript = redis.NewScript(`
+local lock = math.max(redis.call('PTTL', KEYS[3]), redis.call('PTTL', KEYS[6]))
+if lock > 0 then return {1, lock} end
+local delay = math.max(redis.call('PTTL', KEYS[2]), redis.call('PTTL', KEYS[5]))
+if delay > 0 then return {0, delay} end
+if tonumber(redis.call('GET', KEYS[1]) or '0') >= tonumber(ARGV[1])
+	or tonumber(redis.call('GET', KEYS[4]) or '0') >= tonumber(ARGV[2]) then
+	return {0, 1000}
+end
+for _, key in ipairs({KEYS[1], KEYS[4]}) do
+	if redis.call('INCR', key) == 1 then redis.call('PEXPIRE', key, ARGV[3]) end
+end
+return {0, 0}
+`)
+
+// releaseLoginScript başarısız olmayan denemenin ayrılan hakkını geri verir
+var releaseLoginScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if tonumber(redis.call('GET', key) or '0') > 0 then redis.call('DECR', key) end
+end
+return 0
+`)
+
+// ReserveLoginAttempt şifre ya da 2FA kodu kontrol edilmeden önce çağrılır. Hesap ya da IP kilitliyse,
+// bekleme süresi dolmadıysa veya eşik kadar deneme zaten sürüyorsa kalan süreyi döner (locked true ise kilit).
+// Süre 0 ise deneme sayılmıştır: başarısızsa RecordLoginFailure, değilse ReleaseLoginAttempt çağrılmalı.
+func ReserveLoginAttempt(ctx context.Context, rdb *redis.Client, email, ip string) (time.Duration, bool, error) {
+	account, client := accountLoginKeys(email), ipLoginKeys(ip)
+	result, err := reserveLoginScript.Run(ctx, rdb,
+		[]string{account.fails, account.delay, account.lock, client.fails, client.delay, client.lock},
+		AccountLockThreshold, IPLockThreshold, LoginFailureWindow.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return time.Duration(result[1]) * time.Millisecond, result[0] == 1, nil
+}
+
+// ReleaseLoginAttempt doğru şifre ya da kodla biten denemeyi sayaçlardan düşer
+func ReleaseLoginAttempt(ctx context.Context, rdb *redis.Client, email, ip string) error {
+	return releaseLoginScript.Run(ctx, rdb, []string{accountLoginKeys(email).fails, ipLoginKeys(ip).fails}).Err()
+}
+
+// RecordLoginFailure ayrılan deneme başarısız olunca bekleme süresini ayarlar, eşiğe ulaşıldıysa kilitler.
+// Sayaç ReserveLoginAttempt'te artırıldığı için burada tekrar artırılmaz.
+func RecordLoginFailure(ctx context.Context, rdb *redis.Client, email, ip string) (LoginFailure, error) {
+	var result LoginFailure
+	for _, target := range []struct {
+		keys      loginKeys
+		threshold int64
+		locked    *bool
+	}{
+		{accountLoginKeys(email), AccountLockThreshold, &result.AccountLocked},
+		{ipLoginKeys(ip), IPLockThreshold, &result.IPLocked},
+	} {
+		fails, err := rdb.Get(ctx, target.keys.fails).Int64()
+		if err != nil && err != redis.Nil {
+			return result, err
+		}
+
+		if fails >= target.threshold {
+			// Aynı anda biten denemelerden sadece biri kilitler; kilit bitince sayaç sıfırdan başlar
+			locked, err := rdb.SetNX(ctx, target.keys.lock, 1, LockoutDuration).Result()
+			if err != nil {
+				return result, err
+			}
+			if locked {
+				if err := rdb.Del(ctx, target.keys.fails, target.keys.delay).Err(); err != nil {
+					return result, err
+				}
+			}
+			*target.locked = locked
+			continue
+		}
+		if delay := loginDelay(fails); delay > 0 {
+			if err := rdb.Set(ctx, target.keys.delay, 1, delay).Err(); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// ResetLoginFailures başarılı girişten sonra hesabın sayacını sıfırlar; IP sayacı pencere sonunda düşer
+func ResetLoginFailures(ctx context.Context, rdb *redis.Client, email string) error {
+	account := accountLoginKeys(email)
+	return rdb.Del(ctx, account.fails, account.delay).Err()
+}
+
+// AccountLocked hesabın kilitli olup olmadığını ve kalan süreyi döner
+func AccountLocked(ctx context.Context, rdb *redis.Client, email string) (time.Duration, error) {
+	ttl, err := rdb.PTTL(ctx, accountLoginKeys(email).lock).Result()
+	if err != nil {
+		return 0, err
+	}
+	return max(ttl, 0), nil
+}
+
+// UnlockAccount hesabın kilidini, sayacını ve bekleme süresini kaldırır (admin)
+func UnlockAccount(ctx context.Context, rdb *redis.Client, email string) (bool, error) {
+	account := accountLoginKeys(email)
+	pipe := rdb.TxPipeline()
+	unlocked := pipe.Del(ctx, account.lock)
+	pipe.Del(ctx, account.fails, account.delay)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return unlocked.Val() == 1, nil
+}
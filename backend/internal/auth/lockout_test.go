@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		fails int64
+		want  time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{loginDelayAfter - 1, 0},
+		{loginDelayAfter, time.Second},
+		{loginDelayAfter + 1, 2 * time.Second},
+		{loginDelayAfter + 2, 4 * time.Second},
+		{loginDelayAfter + 3, 8 * time.Second},
+		{loginDelayAfter + 4, 16 * time.Second},
+		// 32 sn üst sınıra takılır
+		{loginDelayAfter + 5, maxLoginDelay},
+		{loginDelayAfter + 6, maxLoginDelay},
+		{AccountLockThreshold, maxLoginDelay},
+		// Büyük sayılarda kaydırma taşmaz
+		{1 << 40, maxLoginDelay},
+	}
+	for _, tt := range tests {
+		if got := loginDelay(tt.fails); got != tt.want {
+			t.Errorf("loginDelay(%d) = %v, want %v", tt.fails, got, tt.want)
+		}
+	}
+}
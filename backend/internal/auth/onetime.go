@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tek kullanımlık token amaçları
+const (
+	PurposePasswordReset = "password_reset"
+	PurposeEmailVerify   = "email_verify"
+)
+
+func oneTimeTokenKey(purpose, hash string) string {
+	return fmt.Sprintf("token_%s_%s", purpose, hash)
+}
+
+func userOneTimeTokenKey(purpose string, userID uint) string {
+	return fmt.Sprintf("token_%s_user_%d", purpose, userID)
+}
+
+// NewOneTimeToken kullanıcı için süreli, tek kullanımlık token üretir.
+// Redis'te sadece hash'i tutulur; kullanıcının aynı amaçlı önceki token'ı geçersiz olur.
+func NewOneTimeToken(ctx context.Context, rdb *redis.Client, purpose string, userID uint, ttl time.Duration) (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	hash := hashToken(token)
+
+	previous, err := rdb.Get(ctx, userOneTimeTokenKey(purpose, userID)).Result()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	pipe := rdb.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, oneTimeTokenKey(purpose, previous))
+	}
+	pipe.Set(ctx, oneTimeTokenKey(purpose, hash), userID, ttl)
+	pipe.Set(ctx, userOneTimeTokenKey(purpose, userID), hash, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeOneTimeToken token geçerliyse siler ve sahibinin ID'sini döner.
+// GETDEL atomik olduğu için aynı token ikinci kez kullanılamaz.
+func ConsumeOneTimeToken(ctx context.Context, rdb *redis.Client, purpose, token string) (uint, error) {
+	if token == "" {
+		return 0, ErrInvalidToken
+	}
+
+	userID, err := rdb.GetDel(ctx, oneTimeTokenKey(purpose, hashToken(token))).Uint64()
+	if err == redis.Nil {
+		return 0, ErrInvalidToken
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
+
+// RevokeOneTimeTokens kullanıcının verilen amaçlı açık token'ını iptal eder (ör. şifre değişince reset linki)
+func RevokeOneTimeTokens(ctx context.Context, rdb *redis.Client, purpose string, userID uint) error {
+	hash, err := rdb.GetDel(ctx, userOneTimeTokenKey(purpose, userID)).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return rdb.Del(ctx, oneTimeTokenKey(purpose, hash)).Err()
+}
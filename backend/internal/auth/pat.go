@@ -0,0 +1,26 @@
+package auth
+
+import "strings"
+
+// Kişisel API token'ları bu önekle başlar, middleware JWT'den bu sayede ayırır
+const PersonalTokenPrefix = "tm_pat_"
+
+// IsPersonalToken Authorization'daki değerin kişisel API token'ı olup olmadığını döner
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// NewPersonalToken yeni kişisel API token'ı ve DB'de tutulacak hash'ini üretir; token sadece bir kez gösterilir
+func NewPersonalToken() (token, hash string, err error) {
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", "", err
+	}
+	token = PersonalTokenPrefix + secret
+	return token, HashPersonalToken(token), nil
+}
+
+// HashPersonalToken token'ın DB'de aranan hash'i
+func HashPersonalToken(token string) string {
+	return hashToken(token)
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSessionRevoked = errors.New("session revoked")
+
+// Session Redis'te tutulan oturum bilgisi
+type Session struct {
+	ID             string
+	UserID         uint
+	OrganizationID uint // aktif workspace
+}
+
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("session_%s", sessionID)
+}
+
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("sessions_user_%d", userID)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSession Redis'te yeni bir oturum açar ve refresh token döndürür.
+// Refresh token "<session_id>.<secret>" formatındadır, Redis'te sadece hash'i tutulur.
+func NewSession(ctx context.Context, rdb *redis.Client, userID, organizationID uint) (*Session, string, error) {
+	sessionID, err := randomHex(16)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	refreshToken := sessionID + "." + secret
+
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, sessionKey(sessionID),
+		"user_id", userID,
+		"org_id", organizationID,
+		"refresh_hash", hashToken(refreshToken))
+	pipe.Expire(ctx, sessionKey(sessionID), RefreshTokenTTL)
+	pipe.SAdd(ctx, userSessionsKey(userID), sessionID)
+	pipe.Expire(ctx, userSessionsKey(userID), RefreshTokenTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, "", err
+	}
+
+	return &Session{ID: sessionID, UserID: userID, OrganizationID: organizationID}, refreshToken, nil
+}
+
+// RotateRefreshToken refresh token'ı tek kullanımlık olarak yeniler.
+// Daha önce kullanılmış bir token gelirse oturum çalınmış kabul edilip kapatılır.
+func RotateRefreshToken(ctx context.Context, rdb *redis.Client, refreshToken string) (*Session, string, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" {
+		return nil, "", ErrInvalidToken
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	newRefreshToken := sessionID + "." + secret
+
+	key := sessionKey(sessionID)
+	session := &Session{ID: sessionID}
+	reused := false
+	err = rdb.Watch(ctx, func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return ErrSessionRevoked
+		}
+
+		if subtle.ConstantTimeCompare([]byte(fields["refresh_hash"]), []byte(hashToken(refreshToken))) != 1 {
+			reused = true
+			return ErrInvalidToken
+		}
+
+		userID, err := strconv.ParseUint(fields["user_id"], 10, 64)
+		if err != nil {
+			return err
+		}
+		session.UserID = uint(userID)
+		orgID, _ := strconv.ParseUint(fields["org_id"], 10, 64)
+		session.OrganizationID = uint(orgID)
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "refresh_hash", hashToken(newRefreshToken))
+			pipe.Expire(ctx, key, RefreshTokenTTL)
+			pipe.Expire(ctx, userSessionsKey(session.UserID), RefreshTokenTTL)
+			return nil
+		})
+		return err
+	}, key)
+
+	if reused {
+		RevokeSession(ctx, rdb, sessionID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return session, newRefreshToken, nil
+}
+
+// SetSessionOrganization oturumun aktif workspace'ini değiştirir, refresh sonrası da korunur
+func SetSessionOrganization(ctx context.Context, rdb *redis.Client, sessionID string, organizationID uint) error {
+	n, err := rdb.Exists(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionRevoked
+	}
+	return rdb.HSet(ctx, sessionKey(sessionID), "org_id", organizationID).Err()
+}
+
+// SessionActive oturum iptal edilmemiş ve süresi dolmamışsa true döner
+func SessionActive(ctx context.Context, rdb *redis.Client, sessionID string) (bool, error) {
+	n, err := rdb.Exists(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// RevokeSession tek bir oturumu kapatır (logout)
+func RevokeSession(ctx context.Context, rdb *redis.Client, sessionID string) error {
+	userID, err := rdb.HGet(ctx, sessionKey(sessionID), "user_id").Uint64()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, sessionKey(sessionID))
+	if userID != 0 {
+		pipe.SRem(ctx, userSessionsKey(uint(userID)), sessionID)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RevokeOtherSessions kullanıcının verilen oturum dışındaki tüm oturumlarını kapatır (şifre değişikliği)
+func RevokeOtherSessions(ctx context.Context, rdb *redis.Client, userID uint, keepSessionID string) error {
+	sessionIDs, err := rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	for _, sid := range sessionIDs {
+		if sid == keepSessionID {
+			continue
+		}
+		pipe.Del(ctx, sessionKey(sid))
+		pipe.SRem(ctx, userSessionsKey(userID), sid)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RevokeUserSessions kullanıcının tüm oturumlarını kapatır (şifre değişikliği, admin kill-switch)
+func RevokeUserSessions(ctx context.Context, rdb *redis.Client, userID uint) error {
+	sessionIDs, err := rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := rdb.TxPipeline()
+	for _, sid := range sessionIDs {
+		pipe.Del(ctx, sessionKey(sid))
+	}
+	pipe.Del(ctx, userSessionsKey(userID))
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SSO girişinde IdP'den dönülmesi için süre
+const SSOStateTTL = 10 * time.Minute
+
+func ssoStateKey(state string) string {
+	return fmt.Sprintf("sso_state_%s", hashToken(state))
+}
+
+// SaveSSOState IdP'ye yönlendirmeden önce state'e bağlı PKCE verifier ve nonce'ı saklar.
+// browser girişi başlatan tarayıcının cookie'sindeki değerdir, sadece hash'i tutulur.
+func SaveSSOState(ctx context.Context, rdb *redis.Client, state, verifier, nonce, browser string) error {
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, ssoStateKey(state), "verifier", verifier, "nonce", nonce, "browser", hashToken(browser))
+	pipe.Expire(ctx, ssoStateKey(state), SSOStateTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeSSOState callback'te state'i tek seferlik kullanır, verifier ve nonce'ı döner.
+// State başka bir tarayıcıda başlatıldıysa (login CSRF) reddedilir.
+func ConsumeSSOState(ctx context.Context, rdb *redis.Client, state, browser string) (verifier, nonce string, err error) {
+	if state == "" || browser == "" {
+		return "", "", ErrInvalidToken
+	}
+
+	pipe := rdb.TxPipeline()
+	values := pipe.HGetAll(ctx, ssoStateKey(state))
+	pipe.Del(ctx, ssoStateKey(state))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", "", err
+	}
+	if values.Val()["verifier"] == "" ||
+		subtle.ConstantTimeCompare([]byte(values.Val()["browser"]), []byte(hashToken(browser))) != 1 {
+		return "", "", ErrInvalidToken
+	}
+	return values.Val()["verifier"], values.Val()["nonce"], nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+
+const (
+	// Access token kısa ömürlü, refresh token ile yenilenir
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims access token içeriği
+type Claims struct {
+	UserID         uint   `json:"user_id"`
+	Role           string `json:"role"`
+	OrganizationID uint   `json:"org_id"`
+	SessionID      string `json:"sid"`
+	// E-postasını doğrulamamış kullanıcıların erişimi kısıtlıdır
+	EmailUnverified bool `json:"unv,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateAccessToken verilen claim'ler için imzalı access token üretir
+func GenerateAccessToken(claims Claims) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// ParseAccessToken imzayı ve süreyi doğrular
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenMalformed
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid || claims.UserID == 0 || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// RFC 6238 varsayılanları, authenticator uygulamalarının hepsi destekler
+	totpPeriod = 30
+	totpDigits = 6
+	// Saat kayması için önceki ve sonraki adım da kabul edilir
+	totpSkew = 1
+
+	TOTPIssuer        = "TaskMan"
+	RecoveryCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret 160 bit rastgele secret üretir (base32, padding'siz)
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI authenticator uygulamalarına eklemek için otpauth:// URI, QR kodun içeriği budur
+func TOTPURI(secret, account string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// totpCode verilen zaman adımı için kodu hesaplar (HMAC-SHA1, dinamik kesme)
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// ValidateTOTP kodu saat kayması toleransıyla doğrular ve eşleşen zaman adımını döner.
+// Aynı adımın tekrar kullanılmasını çağıran engellemeli.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// UseTOTPStep zaman adımını kullanıldı olarak işaretler; aynı kod tolerans süresi içinde ikinci kez geçmez
+func UseTOTPStep(ctx context.Context, rdb *redis.Client, userID uint, step int64) (bool, error) {
+	key := fmt.Sprintf("totp_used_%d_%d", userID, step)
+	// Kod önceki, şimdiki ve sonraki adımda geçerli olduğu için o kadar saklanır
+	return rdb.SetNX(ctx, key, 1, (2*totpSkew+1)*totpPeriod*time.Second).Result()
+}
+
+// GenerateRecoveryCodes tek kullanımlık kurtarma kodları üretir, ör. "3f9a2-c41d7"
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		code, err := randomHex(5)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode kurtarma kodunun DB'de tutulan hash'i; tire ve büyük/küçük harf önemsizdir
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return hashToken(code)
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 
 	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/rank"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -27,12 +29,203 @@ func Connect() *gorm.DB {
 		log.Fatal("❌ failed to connect database:", err)
 	}
 
+	// E-posta doğrulaması gelmeden önce açılmış hesaplar doğrulanmış sayılır
+	verifyExisting := !db.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
 	// Tabloları migrate et
-	err = db.AutoMigrate(&models.User{}, &models.Board{}, &models.Task{})
+	err = db.AutoMigrate(
+		&models.User{}, &models.Board{}, &models.Task{}, &models.BoardMember{},
+		&models.Organization{}, &models.OrganizationMember{},
+		&models.BoardColumn{}, &models.ColumnTransition{}, &models.Label{},
+		&models.Comment{}, &models.CommentRevision{}, &models.Notification{}, &models.Attachment{},
+		&models.ChecklistItem{}, &models.TaskDependency{}, &models.AuditEvent{},
+		&models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxEvent{}, &models.OutboxOffset{},
+		&models.NotificationPreference{}, &models.TaskWatcher{}, &models.EmailNotification{},
+		&models.RecoveryCode{}, &models.SecurityEvent{}, &models.PersonalAccessToken{},
+		&models.UserIdentity{},
+	)
 	if err != nil {
 		log.Fatal("❌ failed to run migrations:", err)
 	}
 
+	if verifyExisting {
+		if err := backfillVerifiedEmails(db); err != nil {
+			log.Fatal("❌ failed to backfill verified emails:", err)
+		}
+	}
+	if err := backfillBoardMembers(db); err != nil {
+		log.Fatal("❌ failed to backfill board members:", err)
+	}
+	if err := backfillOrganizations(db); err != nil {
+		log.Fatal("❌ failed to backfill organizations:", err)
+	}
+	if err := backfillColumns(db); err != nil {
+		log.Fatal("❌ failed to backfill board columns:", err)
+	}
+	if err := protectAuditEvents(db); err != nil {
+		log.Fatal("❌ failed to protect audit events:", err)
+	}
+	if err := backfillDoneColumns(db); err != nil {
+		log.Fatal("❌ failed to backfill done columns:", err)
+	}
+	if err := backfillRanks(db); err != nil {
+		log.Fatal("❌ failed to backfill task ranks:", err)
+	}
+
 	log.Println("✅ Database connected & migrated")
 	return db
 }
+
+// backfillVerifiedEmails doğrulama tarihi olmayan mevcut hesapları kayıt tarihinde doğrulanmış sayar
+func backfillVerifiedEmails(db *gorm.DB) error {
+	return db.Model(&models.User{}).Where("email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// backfillBoardMembers eski board'ların sahiplerini owner üye olarak ekler
+func backfillBoardMembers(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO board_members (board_id, user_id, role, created_at, updated_at)
+		SELECT b.id, b.user_id, ?, NOW(), NOW() FROM boards b
+		WHERE NOT EXISTS (SELECT 1 FROM board_members m WHERE m.board_id = b.id AND m.user_id = b.user_id)`,
+		models.BoardRoleOwner).Error
+}
+
+// backfillOrganizations workspace'i olmayan kullanıcılara kişisel workspace açar,
+// eski board'ları sahibinin workspace'ine taşır ve paylaşılan üyeleri workspace'e ekler
+func backfillOrganizations(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		if err := tx.Where("id NOT IN (?)", tx.Model(&models.OrganizationMember{}).Select("user_id")).
+			Find(&users).Error; err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			org := models.Organization{Name: user.Name + " Workspace"}
+			if err := tx.Create(&org).Error; err != nil {
+				return err
+			}
+			member := models.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: models.OrgRoleOwner}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		}
+
+		err := tx.Exec(`UPDATE boards SET organization_id = (
+				SELECT m.organization_id FROM organization_members m
+				WHERE m.user_id = boards.user_id ORDER BY m.id LIMIT 1)
+			WHERE organization_id IS NULL OR organization_id = 0`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+			SELECT DISTINCT b.organization_id, bm.user_id, ?, NOW(), NOW()
+			FROM board_members bm JOIN boards b ON b.id = bm.board_id
+			WHERE NOT EXISTS (SELECT 1 FROM organization_members m
+				WHERE m.organization_id = b.organization_id AND m.user_id = bm.user_id)`,
+			models.OrgRoleMember).Error
+	})
+}
+
+// backfillColumns kolonu olmayan board'lara varsayılan kolonları ve
+// task'larda kullanılan diğer status'leri kolon olarak ekler
+func backfillColumns(db *gorm.DB) error {
+	var boardIDs []uint
+	if err := db.Model(&models.Board{}).
+		Where("id NOT IN (?)", db.Model(&models.BoardColumn{}).Select("board_id")).
+		Pluck("id", &boardIDs).Error; err != nil {
+		return err
+	}
+
+	for _, boardID := range boardIDs {
+		if err := EnsureColumns(db, boardID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureColumns board'un kolonları yoksa varsayılanları ve kullanılan status'leri oluşturur
+func EnsureColumns(db *gorm.DB, boardID uint) error {
+	var count int64
+	if err := db.Model(&models.BoardColumn{}).Where("board_id = ?", boardID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	names := append([]string{}, models.DefaultColumns...)
+	var statuses []string
+	db.Model(&models.Task{}).Where("board_id = ? AND status <> ''", boardID).Distinct().Pluck("status", &statuses)
+	for _, status := range statuses {
+		if !slices.Contains(names, status) {
+			names = append(names, status)
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, name := range names {
+			if err := tx.Create(&models.BoardColumn{BoardID: boardID, Name: name, Position: i, Done: name == models.DefaultDoneColumn}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// protectAuditEvents audit kayıtlarının güncellenmesini ve silinmesini DB seviyesinde engeller
+func protectAuditEvents(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION audit_events_immutable() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit events are immutable';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_events_immutable ON audit_events;
+		CREATE TRIGGER audit_events_immutable BEFORE UPDATE OR DELETE ON audit_events
+			FOR EACH ROW EXECUTE FUNCTION audit_events_immutable();
+	`).Error
+}
+
+// backfillDoneColumns hiç tamamlanmış kolonu olmayan board'larda "done" kolonunu işaretler
+func backfillDoneColumns(db *gorm.DB) error {
+	return db.Model(&models.BoardColumn{}).
+		Where("name = ? AND board_id NOT IN (?)", models.DefaultDoneColumn,
+			db.Model(&models.BoardColumn{}).Select("board_id").Where("done")).
+		Update("done", true).Error
+}
+
+// backfillRanks rank'i olmayan task'lara kolon içinde ID sırasıyla rank verir
+func backfillRanks(db *gorm.DB) error {
+	var columns []struct {
+		BoardID uint
+		Status  string
+	}
+	if err := db.Model(&models.Task{}).Where("rank IS NULL OR rank = ''").
+		Distinct("board_id", "status").Scan(&columns).Error; err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var tasks []models.Task
+			if err := tx.Where("board_id = ? AND status = ?", col.BoardID, col.Status).
+				Order("id").Find(&tasks).Error; err != nil {
+				return err
+			}
+			for i, r := range rank.Spread(len(tasks)) {
+				if err := tx.Model(&tasks[i]).UpdateColumn("rank", r).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
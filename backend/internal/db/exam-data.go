@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ahmetcanc/TaskMan/internal/models"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -12,27 +13,58 @@ func ExamData(database *gorm.DB) {
 
 	// -----------------------------
 	// Örnek User
+	// Şifre Login'deki gibi bcrypt ile saklanır
+	password, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatal("Password hash error:", err)
+	}
 	user := models.User{
 		Name:     "Ahmet Can",
 		Email:    "ahmet@example.com",
-		Password: "123456",
+		Password: string(password),
 	}
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
 
 	if err := database.FirstOrCreate(&user, models.User{Email: user.Email}).Error; err != nil {
 		log.Fatal("User insert error:", err)
 	}
 
+	// -----------------------------
+	// Örnek Workspace
+	var membership models.OrganizationMember
+	if err := database.Where("user_id = ?", user.ID).Order("id").First(&membership).Error; err != nil {
+		org := models.Organization{Name: user.Name + " Workspace"}
+		if err := database.Create(&org).Error; err != nil {
+			log.Fatal("Organization insert error:", err)
+		}
+		membership = models.OrganizationMember{OrganizationID: org.ID, UserID: user.ID, Role: models.OrgRoleOwner}
+		if err := database.Create(&membership).Error; err != nil {
+			log.Fatal("Organization member insert error:", err)
+		}
+	}
+
 	// -----------------------------
 	// Örnek Board
 	board := models.Board{
-		Title:  "Görev Listesi",
-		UserID: user.ID,
+		Title:          "Görev Listesi",
+		UserID:         user.ID,
+		OrganizationID: membership.OrganizationID,
 	}
 
 	if err := database.FirstOrCreate(&board, models.Board{Title: board.Title, UserID: user.ID}).Error; err != nil {
 		log.Fatal("Board insert error:", err)
 	}
 
+	member := models.BoardMember{BoardID: board.ID, UserID: user.ID, Role: models.BoardRoleOwner}
+	if err := database.FirstOrCreate(&member, models.BoardMember{BoardID: board.ID, UserID: user.ID}).Error; err != nil {
+		log.Fatal("Board member insert error:", err)
+	}
+
+	if err := EnsureColumns(database, board.ID); err != nil {
+		log.Fatal("Board column insert error:", err)
+	}
+
 	// -----------------------------
 	// Örnek Tasks
 	tasks := []models.Task{
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// memberBoardIDs kullanıcının aktif workspace'te üye olduğu board ID'leri için subquery
+func memberBoardIDs(db *gorm.DB, userID, orgID uint) *gorm.DB {
+	return db.Model(&models.BoardMember{}).
+		Select("board_members.board_id").
+		Joins("JOIN boards ON boards.id = board_members.board_id").
+		Where("board_members.user_id = ? AND boards.organization_id = ? AND boards.deleted_at IS NULL", userID, orgID)
+}
+
+// orgRole kullanıcının workspace'teki rolünü döner, üye değilse gorm.ErrRecordNotFound
+func orgRole(db *gorm.DB, orgID, userID uint) (string, error) {
+	var member models.OrganizationMember
+	if err := db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// boardRole kullanıcının board'daki rolünü döner, üye değilse gorm.ErrRecordNotFound
+func boardRole(db *gorm.DB, boardID, userID uint) (string, error) {
+	var member models.BoardMember
+	if err := db.Where("board_id = ? AND user_id = ?", boardID, userID).First(&member).Error; err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// findBoardForUser aktif workspace'teki board'u, kullanıcının en az required rolüne sahip olması şartıyla getirir
+func findBoardForUser(db *gorm.DB, boardID any, userID, orgID uint, required string) (models.Board, error) {
+	var board models.Board
+	if err := db.Where("organization_id = ?", orgID).First(&board, boardID).Error; err != nil {
+		return board, err
+	}
+
+	role, err := boardRole(db, board.ID, userID)
+	if err != nil {
+		return board, err
+	}
+	if !models.BoardRoleAllows(role, required) {
+		return board, gorm.ErrRecordNotFound
+	}
+
+	return board, nil
+}
+
+// findTaskForUser task'ı, board'unda en az required rolüne sahip olması şartıyla getirir
+func findTaskForUser(db *gorm.DB, taskID any, userID, orgID uint, required string) (models.Task, error) {
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		return task, err
+	}
+
+	if _, err := findBoardForUser(db, task.BoardID, userID, orgID, required); err != nil {
+		return task, err
+	}
+
+	return task, nil
+}
+
+// boardMemberIDs board'a üye kullanıcıların ID'leri
+func boardMemberIDs(db *gorm.DB, boardID uint) []uint {
+	var userIDs []uint
+	db.Model(&models.BoardMember{}).Where("board_id = ?", boardID).Pluck("user_id", &userIDs)
+	return userIDs
+}
+
+// removeTaskLinks board'lardan çıkarılan kullanıcının oradaki task'larda (çöp kutusundakiler dahil)
+// atanan ve takipçi kayıtlarını siler; boardIDs tek ID, liste ya da subquery olabilir
+func removeTaskLinks(tx *gorm.DB, userID uint, boardIDs any) error {
+	taskIDs := tx.Unscoped().Model(&models.Task{}).Select("id").Where("board_id IN (?)", boardIDs)
+	if err := tx.Exec("DELETE FROM task_assignees WHERE user_id = ? AND task_id IN (?)", userID, taskIDs).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND task_id IN (?)", userID, taskIDs).Delete(&models.TaskWatcher{}).Error
+}
+
+// invalidateBoardCaches board'un tüm üyelerinin cache'lerini temizler
+func invalidateBoardCaches(ctx context.Context, db *gorm.DB, rdb *redis.Client, boardID uint) {
+	var board models.Board
+	if err := db.Select("id", "organization_id").First(&board, boardID).Error; err != nil {
+		return
+	}
+
+	for _, userID := range boardMemberIDs(db, boardID) {
+		invalidateUserCaches(ctx, rdb, userID, board.OrganizationID)
+	}
+}
+
+func boardsCacheKey(userID, orgID uint) string {
+	return fmt.Sprintf("boards_user_%d_org_%d", userID, orgID)
+}
+
+func tasksCacheKey(userID, orgID uint) string {
+	return fmt.Sprintf("tasks_user_%d_org_%d", userID, orgID)
+}
+
+// invalidateUserCaches kullanıcının workspace'teki board ve task listesi cache'lerini temizler
+func invalidateUserCaches(ctx context.Context, rdb *redis.Client, userID, orgID uint) {
+	rdb.Del(ctx, boardsCacheKey(userID, orgID), tasksCacheKey(userID, orgID))
+}
+
+// orgMemberIDs workspace üyelerinin user ID'leri için subquery
+func orgMemberIDs(db *gorm.DB, orgID uint) *gorm.DB {
+	return db.Model(&models.OrganizationMember{}).Select("user_id").Where("organization_id = ?", orgID)
+}
+
+// userOrganizationIDs kullanıcının üye olduğu workspace ID'leri
+func userOrganizationIDs(db *gorm.DB, userID uint) []uint {
+	var orgIDs []uint
+	db.Model(&models.OrganizationMember{}).Where("user_id = ?", userID).Order("id").Pluck("organization_id", &orgIDs)
+	return orgIDs
+}
+
+// defaultOrganizationID login sonrası aktif olacak workspace (ilk katıldığı, 2FA şartını karşıladığı)
+func defaultOrganizationID(db *gorm.DB, userID uint) uint {
+	for _, orgID := range userOrganizationIDs(db, userID) {
+		if twoFactorSatisfied(db, orgID, userID) {
+			return orgID
+		}
+	}
+	return 0
+}
+
+func usersCacheKey(orgID uint) string {
+	return fmt.Sprintf("users_org_%d", orgID)
+}
+
+// invalidateUsersCaches kullanıcının bulunduğu tüm workspace'lerin kullanıcı listesi cache'ini temizler
+func invalidateUsersCaches(ctx context.Context, db *gorm.DB, rdb *redis.Client, userID uint) {
+	for _, orgID := range userOrganizationIDs(db, userID) {
+		rdb.Del(ctx, usersCacheKey(orgID))
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/mail"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	emailVerifyTTL   = 48 * time.Hour
+	// Aynı kullanıcıya bu süre içinde ikinci link e-postası gönderilmez
+	accountEmailCooldown = time.Minute
+	minPasswordLength    = 8
+)
+
+// accountEmailAllowed kullanıcının istediği link e-postalarını (sıfırlama, tekrar doğrulama) sınırlar
+func (h *UserHandler) accountEmailAllowed(purpose string, userID uint) bool {
+	key := fmt.Sprintf("account_email_%s_%d", purpose, userID)
+	ok, err := h.RDB.SetNX(h.Ctx, key, 1, accountEmailCooldown).Result()
+	return err == nil && ok
+}
+
+// sendAccountEmail tek kullanımlık token üretir ve linkini kullanıcıya e-postayla gönderir.
+// SMTP isteği beklenmez, gönderim arka planda yapılır.
+func (h *UserHandler) sendAccountEmail(user models.User, purpose, template, path string, ttl time.Duration) error {
+	token, err := auth.NewOneTimeToken(h.Ctx, h.RDB, purpose, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		msg, err := mail.Render(user.Language, template, user.Email, gin.H{
+			"User":  user,
+			"URL":   mail.AppURL() + path + "?token=" + token,
+			"Hours": int(ttl.Hours()),
+		})
+		if err == nil {
+			err = h.Mailer.Send(msg)
+		}
+		if err != nil {
+			log.Printf("account email (%s) to user %d failed: %v", purpose, user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// sendVerificationEmail kullanıcının mevcut adresine doğrulama linki gönderir
+func (h *UserHandler) sendVerificationEmail(user models.User) error {
+	return h.sendAccountEmail(user, auth.PurposeEmailVerify, "verify_email", "/verify-email", emailVerifyTTL)
+}
+
+// POST /password/forgot - hesap varsa şifre sıfırlama linki gönderir
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	// Hesabın olup olmadığı cevaptan anlaşılmamalı: arama, cooldown ve token arka planda yapılır,
+	// böylece cevap süresi de hesap olsun olmasın aynıdır
+	go h.sendPasswordReset(input.Email)
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a reset link has been sent"})
+}
+
+// sendPasswordReset hesap varsa ve cooldown dolduysa sıfırlama linkini gönderir
+func (h *UserHandler) sendPasswordReset(email string) {
+	var user models.User
+	if err := h.DB.Where("email = ?", email).First(&user).Error; err != nil ||
+		!h.accountEmailAllowed(auth.PurposePasswordReset, user.ID) {
+		return
+	}
+	if err := h.sendAccountEmail(user, auth.PurposePasswordReset, "password_reset", "/reset-password", passwordResetTTL); err != nil {
+		log.Println("password reset token error:", err)
+	}
+}
+
+// POST /password/reset - e-postadaki token ile yeni şifre belirler, tüm oturumları kapatır
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if len(input.Password) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	userID, err := auth.ConsumeOneTimeToken(h.Ctx, h.RDB, auth.PurposePasswordReset, input.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	before := user
+	user.Password = string(hashedPassword)
+	// Link e-postayla geldiği için adres de doğrulanmış olur
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		user.Role = verifiedUserRole(user)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			ActorID: user.ID, Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID,
+			Before: before, After: user, Sensitive: []string{"Password"},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	auth.RevokeUserSessions(h.Ctx, h.RDB, user.ID)
+	auth.RevokeOneTimeTokens(h.Ctx, h.RDB, auth.PurposeEmailVerify, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// POST /email/verify - e-postadaki token ile adresi doğrular
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	userID, err := auth.ConsumeOneTimeToken(h.Ctx, h.RDB, auth.PurposeEmailVerify, input.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		before := user
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		user.Role = verifiedUserRole(user)
+
+		err = h.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(map[string]any{"email_verified_at": now, "role": user.Role}).Error; err != nil {
+				return err
+			}
+			return recordAudit(tx, c, auditEntry{
+				ActorID: user.ID, Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user,
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+			return
+		}
+		invalidateUsersCaches(h.Ctx, h.DB, h.RDB, user.ID)
+	}
+
+	// Açık oturumların access token'ı kısıtlı kalır, /token/refresh ile yenilenmeli
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// POST /email/verify/resend - giriş yapmış, doğrulanmamış kullanıcıya yeni link gönderir
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already verified"})
+		return
+	}
+	if !h.accountEmailAllowed(auth.PurposeEmailVerify, user.ID) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait before requesting another email"})
+		return
+	}
+
+	if err := h.sendVerificationEmail(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
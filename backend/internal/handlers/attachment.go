@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Varsayılan ek boyutu sınırı, MAX_ATTACHMENT_SIZE (byte) ile değiştirilebilir
+const defaultMaxAttachmentSize = 10 << 20
+
+// Yüklenebilecek dosya tipleri, içerikten tespit edilen MIME'a göre kontrol edilir
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"application/zip": true, // docx, xlsx gibi office dosyaları da zip olarak tespit edilir
+	"text/plain":      true,
+	"text/csv":        true,
+}
+
+func maxAttachmentSize() int64 {
+	if size, err := strconv.ParseInt(os.Getenv("MAX_ATTACHMENT_SIZE"), 10, 64); err == nil && size > 0 {
+		return size
+	}
+	return defaultMaxAttachmentSize
+}
+
+// attachmentKey storage'da çakışmayan bir key üretir: tasks/<taskID>/<random><ext>
+func attachmentKey(taskID uint, fileName string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tasks/%d/%s%s", taskID, hex.EncodeToString(b), strings.ToLower(filepath.Ext(fileName))), nil
+}
+
+// removeAttachmentFiles storage'daki dosyaları siler; hatalar kaydı geri almaz, sadece loglanır
+func removeAttachmentFiles(ctx context.Context, store storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Println("attachment delete error:", key, err)
+		}
+	}
+}
+
+// GET /tasks/:id/attachments - task'ın ekleri
+func (h *TaskHandler) GetAttachments(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var attachments []models.Attachment
+	if err := h.DB.Where("task_id = ?", task.ID).Order("created_at, id").Find(&attachments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": attachments})
+}
+
+// POST /tasks/:id/attachments - multipart "file" alanıyla dosya yükle
+func (h *TaskHandler) UploadAttachment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	maxSize := maxAttachmentSize()
+	// Multipart başlıkları için biraz pay bırak, büyük istekler okunmadan kesilsin
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	if header.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	defer file.Close()
+
+	// İstemcinin gönderdiği Content-Type'a güvenme, içerikten tespit et
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file"})
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(sniff[:n]))
+	if contentType == "text/plain" && strings.EqualFold(filepath.Ext(header.Filename), ".csv") {
+		contentType = "text/csv"
+	}
+	if !allowedAttachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "File type not allowed"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload failed"})
+		return
+	}
+
+	key, err := attachmentKey(task.ID, header.Filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload failed"})
+		return
+	}
+
+	if err := h.Storage.Put(c.Request.Context(), key, file, header.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Upload failed"})
+		return
+	}
+
+	attachment := models.Attachment{
+		TaskID:      task.ID,
+		UserID:      userID,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		StorageKey:  key,
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "attachment", EntityID: attachment.ID, BoardID: task.BoardID, TaskID: task.ID, After: attachment,
+		})
+	})
+	if err != nil {
+		removeAttachmentFiles(h.Ctx, h.Storage, []string{key})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": attachment})
+}
+
+// GET /tasks/:id/attachments/:attachmentId - dosyayı indir
+func (h *TaskHandler) DownloadAttachment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var attachment models.Attachment
+	if err := h.DB.Where("id = ? AND task_id = ?", c.Param("attachmentId"), task.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	reader, err := h.Storage.Get(c.Request.Context(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment file missing"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Download failed"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DELETE /tasks/:id/attachments/:attachmentId - eki sil (yükleyen ya da board owner'ı)
+func (h *TaskHandler) DeleteAttachment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var attachment models.Attachment
+	if err := h.DB.Where("id = ? AND task_id = ?", c.Param("attachmentId"), task.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	if attachment.UserID != userID {
+		if role, _ := boardRole(h.DB, task.BoardID, userID); role != models.BoardRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the uploader can delete this attachment"})
+			return
+		}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "attachment", EntityID: attachment.ID, BoardID: task.BoardID, TaskID: task.ID, Before: attachment,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	removeAttachmentFiles(h.Ctx, h.Storage, []string{attachment.StorageKey})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Audit'te gösterilmeyen, her güncellemede değişen alanlar
+var auditIgnoredFields = map[string]bool{"CreatedAt": true, "UpdatedAt": true}
+
+// auditEntry kaydedilecek işlem; Before create'te, After delete'te nil olur
+type auditEntry struct {
+	ActorID    uint // recordAudit'te 0 ise istekteki kullanıcı
+	Action     string
+	EntityType string
+	EntityID   uint
+	BoardID    uint
+	TaskID     uint
+	OrgID      uint // outbox event'i ve audit kaydı için; 0 ise aktif workspace
+	Before     any
+	After      any
+	Sensitive  []string // değeri saklanmadan "değişti" olarak kaydedilen alanlar (ör. Password)
+}
+
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditFields entity'nin JSON'daki düz alanlarını döner, ilişkiler (obje/liste) ve null'lar atlanır
+func auditFields(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+
+	for k, value := range fields {
+		switch value.(type) {
+		case map[string]any, []any, nil:
+			delete(fields, k)
+		}
+		if auditIgnoredFields[k] {
+			delete(fields, k)
+		}
+	}
+	return fields
+}
+
+// auditChanges before → after arasındaki alan farkları
+func auditChanges(before, after any) map[string]auditChange {
+	b := auditFields(before)
+	a := auditFields(after)
+
+	changes := map[string]auditChange{}
+	for k, value := range a {
+		if !reflect.DeepEqual(b[k], value) {
+			changes[k] = auditChange{Before: b[k], After: value}
+		}
+	}
+	for k, value := range b {
+		if _, ok := a[k]; !ok {
+			changes[k] = auditChange{Before: value}
+		}
+	}
+	return changes
+}
+
+func optionalUint(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// recordAudit işlemi aynı transaction içinde kaydeder. Değişiklik olmayan update'ler kaydedilmez.
+func recordAudit(tx *gorm.DB, c *gin.Context, e auditEntry) error {
+	if e.ActorID == 0 {
+		e.ActorID = c.GetUint("user_id")
+	}
+	if e.OrgID == 0 {
+		e.OrgID = c.GetUint("org_id")
+	}
+	if err := emitEvent(tx, c, e); err != nil {
+		return err
+	}
+	return writeAudit(tx, c.GetString("request_id"), e)
+}
+
+// writeAudit HTTP isteği dışındaki işlemler (ör. arka plan temizliği) için de kullanılır
+func writeAudit(tx *gorm.DB, requestID string, e auditEntry) error {
+	changes := auditChanges(e.Before, e.After)
+	for _, field := range e.Sensitive {
+		changes[field] = auditChange{Before: "[redacted]", After: "[redacted]"}
+	}
+	if e.Action == models.AuditUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.AuditEvent{
+		ActorID:        e.ActorID,
+		Action:         e.Action,
+		EntityType:     e.EntityType,
+		EntityID:       e.EntityID,
+		OrganizationID: optionalUint(e.OrgID),
+		BoardID:        optionalUint(e.BoardID),
+		TaskID:         optionalUint(e.TaskID),
+		Changes:        data,
+		RequestID:      requestID,
+	}).Error
+}
+
+// auditPage limit/offset query parametreleri, limit en fazla 200
+func auditPage(query *gorm.DB, c *gin.Context) *gorm.DB {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.Query("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset)
+}
+
+// GET /tasks/:id/history - task'ın ve alt kayıtlarının (yorum, checklist, ek...) geçmişi
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := auditPage(h.DB.Where("task_id = ?", task.ID), c).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
+
+// GET /boards/:id/activity - board'daki tüm işlemlerin akışı
+func (h *BoardHandler) GetActivity(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := auditPage(h.DB.Where("board_id = ?", board.ID), c).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
+
+type AuditHandler struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+	Ctx context.Context
+}
+
+func NewAuditHandler(db *gorm.DB, rdb *redis.Client) *AuditHandler {
+	return &AuditHandler{
+		DB:  db,
+		RDB: rdb,
+		Ctx: context.Background(),
+	}
+}
+
+// GET /audit - aktif workspace'in audit kayıtları (sadece admin).
+// Filtreler: actor_id, action, entity_type, entity_id, board_id, task_id, request_id, from, to
+func (h *AuditHandler) GetAuditEvents(c *gin.Context) {
+	orgID := c.GetUint("org_id")
+	// Workspace'i kaydedilmemiş eski kayıtlar board'un workspace'ine göre gösterilir
+	query := h.DB.Model(&models.AuditEvent{}).Where("organization_id = ? OR (organization_id IS NULL AND board_id IN (?))",
+		orgID, h.DB.Unscoped().Model(&models.Board{}).Select("id").Where("organization_id = ?", orgID))
+
+	for param, column := range map[string]string{
+		"actor_id": "actor_id", "entity_id": "entity_id", "board_id": "board_id", "task_id": "task_id",
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			query = query.Where(column+" = ?", id)
+		}
+	}
+
+	for _, param := range []string{"action", "entity_type", "request_id"} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(param+" = ?", value)
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseTime(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseTime(to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	var events []models.AuditEvent
+	if err := auditPage(query, c).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
@@ -3,27 +3,29 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
 type BoardHandler struct {
-	DB  *gorm.DB
-	RDB *redis.Client
-	Ctx context.Context
+	DB      *gorm.DB
+	RDB     *redis.Client
+	Storage storage.Storage
+	Ctx     context.Context
 }
 
-func NewBoardHandler(db *gorm.DB, rdb *redis.Client) *BoardHandler {
+func NewBoardHandler(db *gorm.DB, rdb *redis.Client, store storage.Storage) *BoardHandler {
 	return &BoardHandler{
-		DB:  db,
-		RDB: rdb,
-		Ctx: context.Background(),
+		DB:      db,
+		RDB:     rdb,
+		Storage: store,
+		Ctx:     context.Background(),
 	}
 }
 
@@ -31,9 +33,10 @@ func NewBoardHandler(db *gorm.DB, rdb *redis.Client) *BoardHandler {
 func (h *BoardHandler) GetBoards(c *gin.Context) {
 	// JWT'den user ID'yi al
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 
-	// Cache key'ini user-specific yap
-	cacheKey := fmt.Sprintf("boards_user_%d", userID)
+	// Cache key'ini user ve workspace'e özel yap
+	cacheKey := boardsCacheKey(userID, orgID)
 
 	cached, err := h.RDB.Get(h.Ctx, cacheKey).Result()
 	if err == nil && cached != "" {
@@ -45,11 +48,20 @@ func (h *BoardHandler) GetBoards(c *gin.Context) {
 	}
 
 	var boards []models.Board
-	// Sadece o kullanıcının board'larını getir
-	if err := h.DB.Where("user_id = ?", userID).Preload("Tasks").Find(&boards).Error; err != nil {
+	// Kullanıcının aktif workspace'te üye olduğu (sahip ya da paylaşılan) board'ları getir
+	if err := h.DB.Where("id IN (?)", memberBoardIDs(h.DB, userID, orgID)).Preload("Tasks", func(db *gorm.DB) *gorm.DB { return db.Order(rankOrder) }).
+		Preload("Tasks.Labels").
+		Preload("Columns", func(db *gorm.DB) *gorm.DB { return db.Order("position, id") }).
+		Find(&boards).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
+	for i := range boards {
+		if err := loadProgress(h.DB, boards[i].Tasks); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+			return
+		}
+	}
 
 	data, _ := json.Marshal(boards)
 	h.RDB.Set(h.Ctx, cacheKey, data, time.Hour)
@@ -61,6 +73,7 @@ func (h *BoardHandler) GetBoards(c *gin.Context) {
 func (h *BoardHandler) CreateBoard(c *gin.Context) {
 	// JWT'den user ID'yi al - frontend'den user_id göndermeye gerek yok
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 
 	var input struct {
 		Title string `json:"title"`
@@ -72,19 +85,40 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 		return
 	}
 
+	// Board aktif workspace'te oluşturulur
+	if _, err := orgRole(h.DB, orgID, userID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of the active workspace"})
+		return
+	}
+
 	board := models.Board{
-		Title:  input.Title,
-		UserID: userID, // JWT'den gelen user ID
+		Title:          input.Title,
+		UserID:         userID, // JWT'den gelen user ID
+		OrganizationID: orgID,
 	}
 
-	if err := h.DB.Create(&board).Error; err != nil {
+	// Board, owner üyeliği ve varsayılan kolonlar birlikte oluşturulur
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&board).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.BoardMember{BoardID: board.ID, UserID: userID, Role: models.BoardRoleOwner}).Error; err != nil {
+			return err
+		}
+		if err := createDefaultColumns(tx, board.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "board", EntityID: board.ID, BoardID: board.ID, After: board,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
 	// User-specific cache'i temizle
-	cacheKey := fmt.Sprintf("boards_user_%d", userID)
-	h.RDB.Del(h.Ctx, cacheKey)
+	invalidateUserCaches(h.Ctx, h.RDB, userID, orgID)
 
 	c.JSON(http.StatusCreated, gin.H{"data": board})
 }
@@ -92,17 +126,19 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 // PUT /boards/:id
 func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 	id := c.Param("id")
 
-	var board models.Board
-	// Önce board'un kullanıcıya ait olup olmadığını kontrol et
-	if err := h.DB.Where("id = ? AND user_id = ?", id, userID).First(&board).Error; err != nil {
+	// Başlığı editor ve owner değiştirebilir
+	board, err := findBoardForUser(h.DB, id, userID, orgID, models.BoardRoleEditor)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
 		return
 	}
 
 	var input struct {
-		Title string `json:"title"`
+		Title               string `json:"title"`
+		EnforceDependencies *bool  `json:"enforce_dependencies"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -110,16 +146,35 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 		return
 	}
 
-	board.Title = input.Title
+	before := board
+	if input.Title != "" {
+		board.Title = input.Title
+	}
 
-	if err := h.DB.Save(&board).Error; err != nil {
+	// Workflow ayarlarını sadece owner değiştirebilir
+	if input.EnforceDependencies != nil {
+		if role, _ := boardRole(h.DB, board.ID, userID); role != models.BoardRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can change board settings"})
+			return
+		}
+		board.EnforceDependencies = *input.EnforceDependencies
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&board).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "board", EntityID: board.ID, BoardID: board.ID, Before: before, After: board,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
-	// User-specific cache'i temizle
-	cacheKey := fmt.Sprintf("boards_user_%d", userID)
-	h.RDB.Del(h.Ctx, cacheKey)
+	// Tüm üyelerin cache'ini temizle
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
 
 	c.JSON(http.StatusOK, gin.H{"data": board})
 }
@@ -127,25 +182,40 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 // DELETE /boards/:id
 func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 	id := c.Param("id")
 
-	var board models.Board
-	// Önce board'un kullanıcıya ait olup olmadığını kontrol et
-	if err := h.DB.Where("id = ? AND user_id = ?", id, userID).First(&board).Error; err != nil {
+	// Board'u sadece owner silebilir
+	board, err := findBoardForUser(h.DB, id, userID, orgID, models.BoardRoleOwner)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
 		return
 	}
 
-	if err := h.DB.Delete(&board).Error; err != nil {
+	memberIDs := boardMemberIDs(h.DB, board.ID)
+
+	// Board ve task'ları aynı DeletedAt ile çöp kutusuna taşınır, böylece birlikte geri yüklenebilir
+	before := board
+	now := time.Now()
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).Where("board_id = ?", board.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&board).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "board", EntityID: board.ID, BoardID: board.ID, Before: before,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
-	// User-specific cache'leri temizle
-	cacheKey := fmt.Sprintf("boards_user_%d", userID)
-	tasksCacheKey := fmt.Sprintf("tasks_user_%d", userID)
-	h.RDB.Del(h.Ctx, cacheKey)
-	h.RDB.Del(h.Ctx, tasksCacheKey) // board silindi → o board'a ait tasks da değişti
+	for _, memberID := range memberIDs {
+		invalidateUserCaches(h.Ctx, h.RDB, memberID, board.OrganizationID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Board deleted"})
 }
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// findChecklistItem task'a ait checklist maddesini getirir
+func findChecklistItem(db *gorm.DB, taskID uint, itemID string) (models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	err := db.Where("id = ? AND task_id = ?", itemID, taskID).First(&item).Error
+	return item, err
+}
+
+// validateChecklistAssignee maddeye sadece board üyeleri atanabilir
+func validateChecklistAssignee(db *gorm.DB, boardID uint, assigneeID *uint) error {
+	if assigneeID == nil {
+		return nil
+	}
+	if _, err := boardRole(db, boardID, *assigneeID); err != nil {
+		return &fieldError{Message: "Assignee must be a member of the board"}
+	}
+	return nil
+}
+
+// GET /tasks/:id/checklist - task'ın checklist maddeleri (sıralı)
+func (h *TaskHandler) GetChecklist(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := h.DB.Where("task_id = ?", task.ID).Preload("Assignee").Order("position, id").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// POST /tasks/:id/checklist - madde ekle, pozisyon verilmezse sona
+func (h *TaskHandler) CreateChecklistItem(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Title      string `json:"title"`
+		Done       bool   `json:"done"`
+		Position   *int   `json:"position"`
+		AssigneeID *uint  `json:"assignee_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Title) == "" || len(input.Title) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := validateChecklistAssignee(h.DB, task.BoardID, input.AssigneeID); err != nil {
+		respondTaskWriteError(c, err)
+		return
+	}
+
+	item := models.ChecklistItem{
+		TaskID:     task.ID,
+		Title:      input.Title,
+		Done:       input.Done,
+		AssigneeID: input.AssigneeID,
+	}
+
+	if input.Position != nil {
+		item.Position = *input.Position
+	} else {
+		var maxPosition *int
+		h.DB.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Select("MAX(position)").Scan(&maxPosition)
+		if maxPosition != nil {
+			item.Position = *maxPosition + 1
+		}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "checklist_item", EntityID: item.ID, BoardID: task.BoardID, TaskID: task.ID, After: item,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+
+	c.JSON(http.StatusCreated, gin.H{"data": item})
+}
+
+// PUT /tasks/:id/checklist/:itemId - maddeyi düzenle, işaretle, taşı ya da ata
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	item, err := findChecklistItem(h.DB, task.ID, c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return
+	}
+
+	var input struct {
+		Title      string     `json:"title"`
+		Done       *bool      `json:"done"`
+		Position   *int       `json:"position"`
+		AssigneeID optionalID `json:"assignee_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.Title) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := item
+	if strings.TrimSpace(input.Title) != "" {
+		item.Title = input.Title
+	}
+	if input.Done != nil {
+		item.Done = *input.Done
+	}
+	if input.Position != nil {
+		item.Position = *input.Position
+	}
+	if input.AssigneeID.Set {
+		if err := validateChecklistAssignee(h.DB, task.BoardID, input.AssigneeID.Value); err != nil {
+			respondTaskWriteError(c, err)
+			return
+		}
+		item.AssigneeID = input.AssigneeID.Value
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Assignee").Save(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "checklist_item", EntityID: item.ID, BoardID: task.BoardID, TaskID: task.ID, Before: before, After: item,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+
+	c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// DELETE /tasks/:id/checklist/:itemId
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	item, err := findChecklistItem(h.DB, task.ID, c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "checklist_item", EntityID: item.ID, BoardID: task.BoardID, TaskID: task.ID, Before: item,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item deleted"})
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// mentionedUserIDs yorumdaki @isim geçişlerini board üyelerine çözer, yazarı hariç tutar.
+// İsimler boşluk içerebildiği için üyelerin adları metinde aranır.
+func mentionedUserIDs(db *gorm.DB, boardID uint, body string, authorID uint) ([]uint, error) {
+	if !strings.Contains(body, "@") {
+		return nil, nil
+	}
+
+	var members []models.User
+	if err := db.Where("id IN (?)", db.Model(&models.BoardMember{}).Select("user_id").Where("board_id = ?", boardID)).
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(body)
+	var ids []uint
+	for _, member := range members {
+		if member.ID != authorID && member.Name != "" && containsMention(text, "@"+strings.ToLower(member.Name)) {
+			ids = append(ids, member.ID)
+		}
+	}
+	return ids, nil
+}
+
+// containsMention needle'ın ardından harf/rakam gelmeyen bir geçişini arar (@ali, @alice'i eşlemesin)
+func containsMention(text, needle string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], needle)
+		if i < 0 {
+			return false
+		}
+		end := offset + i + len(needle)
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if end == len(text) || !(unicode.IsLetter(next) || unicode.IsDigit(next) || next == '_') {
+			return true
+		}
+		offset = end
+	}
+}
+
+// findComment task'a ait yorumu getirir
+func findComment(db *gorm.DB, taskID uint, commentID string) (models.Comment, error) {
+	var comment models.Comment
+	err := db.Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error
+	return comment, err
+}
+
+// deleteTaskComments task'ların yorumlarını, revizyonlarını ve bildirimlerini siler
+func deleteTaskComments(tx *gorm.DB, taskIDs []uint) error {
+	commentIDs := tx.Model(&models.Comment{}).Select("id").Where("task_id IN ?", taskIDs)
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.CommentRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("task_id IN ?", taskIDs).Delete(&models.Comment{}).Error
+}
+
+// GET /tasks/:id/comments - task'ın yorumları (eskiden yeniye)
+func (h *TaskHandler) GetComments(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var comments []models.Comment
+	if err := h.DB.Where("task_id = ?", task.ID).Preload("User").Order("created_at, id").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comments})
+}
+
+// POST /tasks/:id/comments - yorum ekle, bahsedilen üyelere bildirim gider
+func (h *TaskHandler) CreateComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Body string `json:"body"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Body) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	mentions, err := mentionedUserIDs(h.DB, task.BoardID, input.Body, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	comment := models.Comment{TaskID: task.ID, UserID: userID, Body: input.Body}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		// Yorum yapan task'ı otomatik izler
+		if err := watchTask(tx, task.ID, userID); err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "comment", EntityID: comment.ID, BoardID: task.BoardID, TaskID: task.ID, After: comment,
+		}); err != nil {
+			return err
+		}
+		return notify(tx, mentions, models.Notification{
+			ActorID:   userID,
+			Type:      models.NotificationMention,
+			TaskID:    task.ID,
+			CommentID: &comment.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": comment, "mentions": mentions})
+}
+
+// PUT /tasks/:id/comments/:commentId - yorumu düzenle (sadece yazarı), eski metin revizyon olarak saklanır
+func (h *TaskHandler) UpdateComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	comment, err := findComment(h.DB, task.ID, c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	if comment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this comment"})
+		return
+	}
+
+	var input struct {
+		Body string `json:"body"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Body) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Body == comment.Body {
+		c.JSON(http.StatusOK, gin.H{"data": comment})
+		return
+	}
+
+	// Sadece bu düzenlemeyle yeni bahsedilenlere bildirim gider
+	previous, err := mentionedUserIDs(h.DB, task.BoardID, comment.Body, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	mentions, err := mentionedUserIDs(h.DB, task.BoardID, input.Body, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	alreadyNotified := map[uint]bool{}
+	for _, id := range previous {
+		alreadyNotified[id] = true
+	}
+	var newMentions []uint
+	for _, id := range mentions {
+		if !alreadyNotified[id] {
+			newMentions = append(newMentions, id)
+		}
+	}
+
+	before := comment
+	now := time.Now()
+	revision := models.CommentRevision{CommentID: comment.ID, Body: comment.Body}
+	comment.Body = input.Body
+	comment.EditedAt = &now
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&comment).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "comment", EntityID: comment.ID, BoardID: task.BoardID, TaskID: task.ID, Before: before, After: comment,
+		}); err != nil {
+			return err
+		}
+		return notify(tx, newMentions, models.Notification{
+			ActorID:   userID,
+			Type:      models.NotificationMention,
+			TaskID:    task.ID,
+			CommentID: &comment.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comment, "mentions": mentions})
+}
+
+// GET /tasks/:id/comments/:commentId/revisions - yorumun önceki halleri (yeniden eskiye)
+func (h *TaskHandler) GetCommentRevisions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	comment, err := findComment(h.DB, task.ID, c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	var revisions []models.CommentRevision
+	if err := h.DB.Where("comment_id = ?", comment.ID).Order("created_at DESC, id DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": revisions})
+}
+
+// DELETE /tasks/:id/comments/:commentId - yorumu sil (yazarı ya da board owner'ı)
+func (h *TaskHandler) DeleteComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	comment, err := findComment(h.DB, task.ID, c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	if comment.UserID != userID {
+		if role, _ := boardRole(h.DB, task.BoardID, userID); role != models.BoardRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can delete this comment"})
+			return
+		}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "comment", EntityID: comment.ID, BoardID: task.BoardID, TaskID: task.ID, Before: comment,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
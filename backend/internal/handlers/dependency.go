@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Bağımlılık eklemeleri aynı anda döngü oluşturmasın diye transaction boyunca tutulan kilit
+const dependencyLockKey = 7301
+
+var errDependencyExists = errors.New("dependency already exists")
+
+// openBlockerIDs task'ı bloklayan ve henüz tamamlanmamış task'lar
+func openBlockerIDs(db *gorm.DB, taskID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.Task{}).
+		Where("tasks.id IN (?)", db.Model(&models.TaskDependency{}).Select("blocker_id").Where("blocked_id = ?", taskID)).
+		Where(openTaskCondition).Order("tasks.id").Pluck("tasks.id", &ids).Error
+	return ids, err
+}
+
+// createsCycle blockerID → blockedID eklenirse döngü oluşur mu: blockedID zaten (dolaylı olarak) blockerID'yi blokluyorsa
+func createsCycle(db *gorm.DB, blockerID, blockedID uint) (bool, error) {
+	var exists bool
+	err := db.Raw(`WITH RECURSIVE downstream(id) AS (
+			SELECT blocked_id FROM task_dependencies WHERE blocker_id = ?
+			UNION
+			SELECT d.blocked_id FROM task_dependencies d JOIN downstream ON d.blocker_id = downstream.id
+		)
+		SELECT EXISTS (SELECT 1 FROM downstream WHERE id = ?)`, blockedID, blockerID).Scan(&exists).Error
+	return exists, err
+}
+
+// loadDependencies task'ın blocker ve dependent'larını, kullanıcının erişebildiği board'lardan doldurur
+func loadDependencies(db *gorm.DB, task *models.Task, userID, orgID uint) error {
+	visible := db.Where("tasks.board_id IN (?)", memberBoardIDs(db, userID, orgID)).Order("tasks.id").Session(&gorm.Session{})
+
+	if err := visible.
+		Where("tasks.id IN (?)", db.Model(&models.TaskDependency{}).Select("blocker_id").Where("blocked_id = ?", task.ID)).
+		Find(&task.Blockers).Error; err != nil {
+		return err
+	}
+	return visible.
+		Where("tasks.id IN (?)", db.Model(&models.TaskDependency{}).Select("blocked_id").Where("blocker_id = ?", task.ID)).
+		Find(&task.Dependents).Error
+}
+
+// GET /tasks/:id/dependencies - task'ı bloklayanlar ve task'ın blokladıkları
+func (h *TaskHandler) GetDependencies(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	if err := loadDependencies(h.DB, &task, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blockers": task.Blockers, "dependents": task.Dependents})
+}
+
+// POST /tasks/:id/dependencies - {"blocker_id": 5}: task 5 bitmeden bu task bitirilemez
+func (h *TaskHandler) AddDependency(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var input struct {
+		BlockerID uint `json:"blocker_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.BlockerID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.BlockerID == task.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task cannot block itself"})
+		return
+	}
+
+	// Blocker başka bir board'da olabilir, kullanıcının okuyabilmesi yeterli
+	blocker, err := findTaskForUser(h.DB, input.BlockerID, userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Blocker task not found or access denied"})
+		return
+	}
+
+	dependency := models.TaskDependency{BlockerID: blocker.ID, BlockedID: task.ID, UserID: userID}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", dependencyLockKey).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.TaskDependency{}).
+			Where("blocker_id = ? AND blocked_id = ?", blocker.ID, task.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errDependencyExists
+		}
+
+		cycle, err := createsCycle(tx, blocker.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return &workflowError{
+				Message: "Dependency would create a cycle",
+				Details: gin.H{"blocker_id": blocker.ID, "blocked_id": task.ID},
+			}
+		}
+
+		if err := tx.Create(&dependency).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "dependency", EntityID: dependency.ID, BoardID: task.BoardID, TaskID: task.ID, After: dependency,
+		})
+	})
+	if errors.Is(err, errDependencyExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Dependency already exists"})
+		return
+	}
+	if err != nil {
+		respondTaskWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": dependency})
+}
+
+// DELETE /tasks/:id/dependencies/:blockerId
+func (h *TaskHandler) RemoveDependency(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var dependency models.TaskDependency
+	if err := h.DB.Where("blocker_id = ? AND blocked_id = ?", c.Param("blockerId"), task.ID).First(&dependency).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&dependency).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "dependency", EntityID: dependency.ID, BoardID: task.BoardID, TaskID: task.ID, Before: dependency,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed"})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Etiket rengi #RRGGBB formatında olmalı
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// findLabel board'a ait etiketi getirir
+func findLabel(db *gorm.DB, boardID uint, labelID string) (models.Label, error) {
+	var label models.Label
+	err := db.Where("id = ? AND board_id = ?", labelID, boardID).First(&label).Error
+	return label, err
+}
+
+func labelNameTaken(db *gorm.DB, boardID uint, name string, excludeID uint) bool {
+	var count int64
+	db.Model(&models.Label{}).Where("board_id = ? AND name = ? AND id <> ?", boardID, name, excludeID).Count(&count)
+	return count > 0
+}
+
+// GET /boards/:id/labels - board'un etiket kataloğu
+func (h *BoardHandler) GetLabels(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var labels []models.Label
+	if err := h.DB.Where("board_id = ?", board.ID).Order("name").Find(&labels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": labels})
+}
+
+// POST /boards/:id/labels - yeni etiket (owner ve editor)
+func (h *BoardHandler) CreateLabel(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == "" || len(input.Name) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	label := models.Label{BoardID: board.ID, Name: input.Name, Color: "#808080"}
+	if input.Color != "" {
+		if !labelColorPattern.MatchString(input.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid color"})
+			return
+		}
+		label.Color = input.Color
+	}
+
+	if labelNameTaken(h.DB, board.ID, label.Name, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Label already exists"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&label).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "label", EntityID: label.ID, BoardID: board.ID, After: label,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusCreated, gin.H{"data": label})
+}
+
+// PUT /boards/:id/labels/:labelId - etiketin adını ya da rengini değiştir (owner ve editor)
+func (h *BoardHandler) UpdateLabel(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	label, err := findLabel(h.DB, board.ID, c.Param("labelId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return
+	}
+
+	var input struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.Name) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := label
+	if input.Name != "" {
+		if labelNameTaken(h.DB, board.ID, input.Name, label.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Label already exists"})
+			return
+		}
+		label.Name = input.Name
+	}
+	if input.Color != "" {
+		if !labelColorPattern.MatchString(input.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid color"})
+			return
+		}
+		label.Color = input.Color
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&label).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "label", EntityID: label.ID, BoardID: board.ID, Before: before, After: label,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": label})
+}
+
+// DELETE /boards/:id/labels/:labelId - etiketi sil, task'lardan da kaldırılır (owner ve editor)
+func (h *BoardHandler) DeleteLabel(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	label, err := findLabel(h.DB, board.ID, c.Param("labelId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&label).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "label", EntityID: label.ID, BoardID: board.ID, Before: label,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted"})
+}
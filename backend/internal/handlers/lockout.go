@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// retryAfter Retry-After header'ı için saniye, yukarı yuvarlanır
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// loginBlocked denemeyi şifre kontrolünden önce sayar; hesap ya da IP kilitliyse veya bekleme süresi
+// dolmadıysa 429 döner. Hesap olsun olmasın aynı cevap verilir. Denemenin sonucu loginFailed ya da
+// loginAttemptPassed ile bildirilmeli.
+func (h *UserHandler) loginBlocked(c *gin.Context, email string) bool {
+	wait, locked, err := auth.ReserveLoginAttempt(h.Ctx, h.RDB, email, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return true
+	}
+	if wait <= 0 {
+		return false
+	}
+
+	message := "Too many attempts, slow down"
+	if locked {
+		message = "Too many failed attempts, try again later"
+	}
+	c.Header("Retry-After", retryAfter(wait))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message})
+	return true
+}
+
+// loginFailed başarısız şifre ya da 2FA kodundan sonra bekleme süresini ayarlar, kilitlenme olduysa security event yazar.
+// user e-postaya ait hesap yoksa nil'dir.
+func (h *UserHandler) loginFailed(c *gin.Context, email string, user *models.User) {
+	result, err := auth.RecordLoginFailure(h.Ctx, h.RDB, email, c.ClientIP())
+	if err != nil {
+		log.Println("login failure not recorded:", err)
+		return
+	}
+
+	var userID *uint
+	if user != nil {
+		userID = &user.ID
+	}
+	if result.AccountLocked {
+		h.recordSecurityEvent(c, models.SecurityEvent{Type: models.SecurityAccountLocked, UserID: userID, Email: email})
+	}
+	if result.IPLocked {
+		h.recordSecurityEvent(c, models.SecurityEvent{Type: models.SecurityIPLocked, Email: email})
+	}
+}
+
+// loginAttemptPassed doğru şifre ya da kodla biten denemeyi sayaçlardan düşer
+func (h *UserHandler) loginAttemptPassed(c *gin.Context, email string) {
+	if err := auth.ReleaseLoginAttempt(h.Ctx, h.RDB, email, c.ClientIP()); err != nil {
+		log.Println("login attempt not released:", err)
+	}
+}
+
+// loginSucceeded giriş tamamlanınca hesabın sayacını sıfırlar
+func (h *UserHandler) loginSucceeded(email string) {
+	if err := auth.ResetLoginFailures(h.Ctx, h.RDB, email); err != nil {
+		log.Println("login failures not reset:", err)
+	}
+}
+
+func (h *UserHandler) recordSecurityEvent(c *gin.Context, event models.SecurityEvent) {
+	event.IP = c.ClientIP()
+	event.RequestID = c.GetString("request_id")
+	if err := h.DB.Create(&event).Error; err != nil {
+		log.Println("security event not recorded:", err)
+	}
+}
+
+// POST /users/:id/unlock - kilitlenen hesabın kilidini ve başarısız deneme sayacını kaldırır (admin)
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	user, err := h.findUserInTenant(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	unlocked, err := auth.UnlockAccount(h.Ctx, h.RDB, user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+	if !unlocked {
+		c.JSON(http.StatusOK, gin.H{"message": "Account is not locked"})
+		return
+	}
+
+	actorID := c.GetUint("user_id")
+	h.recordSecurityEvent(c, models.SecurityEvent{
+		Type:    models.SecurityAccountUnlocked,
+		UserID:  &user.ID,
+		ActorID: &actorID,
+		Email:   user.Email,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
+
+// GET /security/events - aktif workspace üyelerinin güvenlik olayları (sadece admin). Filtreler: type, user_id, ip, from, to
+func (h *AuditHandler) GetSecurityEvents(c *gin.Context) {
+	// Hesaba bağlı olmayan olaylar (ör. IP kilidi) hiçbir workspace'e ait değildir, gösterilmez
+	query := h.DB.Model(&models.SecurityEvent{}).Where("user_id IN (?)", orgMemberIDs(h.DB, c.GetUint("org_id")))
+
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", id)
+	}
+
+	for _, param := range []string{"type", "ip"} {
+		if value := c.Query(param); value != "" {
+			query = query.Where(param+" = ?", value)
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseTime(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseTime(to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	var events []models.SecurityEvent
+	if err := auditPage(query, c).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/mail"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// emailInUse adres başka bir hesapta kayıtlı mı
+func emailInUse(db *gorm.DB, email string, exceptID uint) bool {
+	var count int64
+	db.Model(&models.User{}).Where("email = ? AND id <> ?", email, exceptID).Count(&count)
+	return count > 0
+}
+
+// validEmail basit format kontrolü, asıl doğrulama e-postadaki linkle yapılır
+func validEmail(email string) bool {
+	at := strings.Index(email, "@")
+	return at > 0 && at < len(email)-1 && !strings.ContainsAny(email, " \t\r\n")
+}
+
+// afterEmailChange eski adrese gönderilmiş linkleri iptal eder, yeni adrese doğrulama linki gönderir
+func (h *UserHandler) afterEmailChange(user models.User) {
+	auth.RevokeOneTimeTokens(h.Ctx, h.RDB, auth.PurposePasswordReset, user.ID)
+	if err := h.sendVerificationEmail(user); err != nil {
+		log.Println("verification email error:", err)
+	}
+}
+
+// checkPassword verilen şifre kullanıcının mevcut şifresi mi
+func checkPassword(user models.User, password string) bool {
+	return password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// respondWrongPassword şifre doğrulanamayınca 403 döner; SSO ile açılmış şifresiz hesaba şifre belirlemeyi söyler
+func respondWrongPassword(c *gin.Context, user models.User) {
+	if user.Password == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account has no password, set one with POST /password/forgot"})
+		return
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+}
+
+// GET /me - giriş yapmış kullanıcının hesabı
+func (h *UserHandler) GetMe(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user, "organization_id": c.GetUint("org_id"), "has_password": user.Password != ""})
+}
+
+// PATCH /me - profil alanları; e-posta değişikliği mevcut şifreyi ister ve adresi yeniden doğrulatır
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var input struct {
+		Name            *string `json:"name"`
+		Email           *string `json:"email"`
+		Language        *string `json:"language"`
+		CurrentPassword string  `json:"current_password"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := user
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+			return
+		}
+		user.Name = name
+	}
+
+	if input.Language != nil {
+		if !slices.Contains(mail.Languages, *input.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language"})
+			return
+		}
+		user.Language = *input.Language
+	}
+
+	emailChanged := false
+	if input.Email != nil && strings.TrimSpace(*input.Email) != user.Email {
+		email := strings.TrimSpace(*input.Email)
+		if !validEmail(email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+			return
+		}
+		if !checkPassword(user, input.CurrentPassword) {
+			respondWrongPassword(c, user)
+			return
+		}
+		if emailInUse(h.DB, email, user.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+		user.Email = email
+		// Yeni adres doğrulanana kadar erişim kısıtlanır
+		user.EmailVerifiedAt = nil
+		emailChanged = true
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Select("name", "email", "language", "email_verified_at").Updates(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	if emailChanged {
+		// Diğer cihazlar yeni (kısıtlı) token almak zorunda kalır, bu oturum /token/refresh ile günceller
+		auth.RevokeOtherSessions(h.Ctx, h.RDB, user.ID, c.GetString("session_id"))
+		h.afterEmailChange(user)
+	}
+
+	invalidateUsersCaches(h.Ctx, h.DB, h.RDB, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// POST /me/password - mevcut şifreyle yeni şifre belirler, diğer oturumları kapatır
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var input struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !checkPassword(user, input.CurrentPassword) {
+		respondWrongPassword(c, user)
+		return
+	}
+	if len(input.NewPassword) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Sensitive: []string{"Password"},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	// Bu oturum açık kalır, diğer cihazlar tekrar giriş yapmalı
+	auth.RevokeOtherSessions(h.Ctx, h.RDB, user.ID, c.GetString("session_id"))
+	auth.RevokeOneTimeTokens(h.Ctx, h.RDB, auth.PurposePasswordReset, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// DELETE /me - şifre ve e-posta adresi onayıyla hesabı ve kullanıcının board'larını siler
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var input struct {
+		Password string `json:"password"`
+		Confirm  string `json:"confirm"` // hesabın e-posta adresi
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(input.Confirm), user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Type your email address in confirm to delete the account"})
+		return
+	}
+	if !checkPassword(user, input.Password) {
+		respondWrongPassword(c, user)
+		return
+	}
+
+	h.removeAccount(c, user)
+}
+
+// soleOwnedOrganizations kullanıcının tek owner'ı olduğu ve başka üyeleri de bulunan workspace'ler.
+// Hesap silinmeden önce bunların sahipliği devredilmeli.
+func soleOwnedOrganizations(db *gorm.DB, userID uint) ([]models.Organization, error) {
+	owned := db.Model(&models.OrganizationMember{}).Select("organization_id").
+		Where("user_id = ? AND role = ?", userID, models.OrgRoleOwner)
+	otherOwners := db.Model(&models.OrganizationMember{}).Select("organization_id").
+		Where("user_id <> ? AND role = ?", userID, models.OrgRoleOwner)
+	otherMembers := db.Model(&models.OrganizationMember{}).Select("organization_id").
+		Where("user_id <> ?", userID)
+
+	var orgs []models.Organization
+	err := db.Where("id IN (?) AND id NOT IN (?) AND id IN (?)", owned, otherOwners, otherMembers).Find(&orgs).Error
+	return orgs, err
+}
+
+// removeAccount hesabı siler; oturumları, açık linkleri, dosyaları ve cache'leri temizler
+func (h *UserHandler) removeAccount(c *gin.Context, user models.User) {
+	blocking, err := soleOwnedOrganizations(h.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if len(blocking) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Transfer ownership of these organizations before deleting the account",
+			"organizations": blocking,
+		})
+		return
+	}
+
+	// Silmeden önce hangi workspace cache'lerinin etkileneceğini al
+	orgIDs := userOrganizationIDs(h.DB, user.ID)
+	var members []models.OrganizationMember
+	h.DB.Where("organization_id IN ?", orgIDs).Find(&members)
+
+	var files []string
+	err = h.DB.Transaction(func(tx *gorm.DB) (err error) {
+		files, err = deleteAccount(tx, c, user)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	auth.RevokeUserSessions(h.Ctx, h.RDB, user.ID)
+	auth.RevokeOneTimeTokens(h.Ctx, h.RDB, auth.PurposePasswordReset, user.ID)
+	auth.RevokeOneTimeTokens(h.Ctx, h.RDB, auth.PurposeEmailVerify, user.ID)
+	removeAttachmentFiles(h.Ctx, h.Storage, files)
+
+	// Cache temizle
+	for _, member := range members {
+		invalidateUserCaches(h.Ctx, h.RDB, member.UserID, member.OrganizationID)
+	}
+	for _, orgID := range orgIDs {
+		h.RDB.Del(h.Ctx, usersCacheKey(orgID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+// deleteAccount kullanıcıyı ve ona ait verileri siler:
+// tek üyesi olduğu workspace'ler ve başka owner'ı olmayan board'ları tamamen silinir,
+// paylaşılan board'larda sahiplik diğer owner'a geçer, üyelik/atama/yorum/bildirimleri kaldırılır.
+// Silinen eklerin storage key'lerini döner, dosyalar commit'ten sonra silinmeli.
+func deleteAccount(tx *gorm.DB, c *gin.Context, user models.User) ([]string, error) {
+	var soloOrgIDs []uint
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id IN (?)", tx.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", user.ID)).
+		Group("organization_id").Having("COUNT(*) = 1").
+		Pluck("organization_id", &soloOrgIDs).Error; err != nil {
+		return nil, err
+	}
+
+	var boards []models.Board
+	otherOwners := tx.Model(&models.BoardMember{}).Select("board_id").Where("user_id <> ? AND role = ?", user.ID, models.BoardRoleOwner)
+	ownBoards := tx.Model(&models.BoardMember{}).Select("board_id").Where("user_id = ? AND role = ?", user.ID, models.BoardRoleOwner)
+	if err := tx.Unscoped().
+		Where("organization_id IN ?", soloOrgIDs).
+		Or("(user_id = ? OR id IN (?)) AND id NOT IN (?)", user.ID, ownBoards, otherOwners).
+		Find(&boards).Error; err != nil {
+		return nil, err
+	}
+
+	var files []string
+	boardIDs := make([]uint, 0, len(boards))
+	for _, board := range boards {
+		boardFiles, err := purgeBoard(tx, board.ID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, boardFiles...)
+		boardIDs = append(boardIDs, board.ID)
+
+		// Çöp kutusundaki board'lar için sadece purge kaydı, diğerleri board.deleted olarak yayınlanır
+		action := models.AuditDelete
+		if board.DeletedAt.Valid {
+			action = models.AuditPurge
+		}
+		if err := recordAudit(tx, c, auditEntry{
+			Action: action, EntityType: "board", EntityID: board.ID, BoardID: board.ID, OrgID: board.OrganizationID, Before: board,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Kalan board'larda sahiplik diğer owner'a geçer
+	if err := tx.Exec(`UPDATE boards SET user_id = (
+			SELECT m.user_id FROM board_members m
+			WHERE m.board_id = boards.id AND m.user_id <> ? AND m.role = ? ORDER BY m.id LIMIT 1)
+		WHERE user_id = ?`, user.ID, models.BoardRoleOwner, user.ID).Error; err != nil {
+		return nil, err
+	}
+
+	// Silinen workspace'lerin ve board'ların webhook'ları
+	hookIDs := tx.Model(&models.Webhook{}).Select("id").Where("organization_id IN ? OR board_id IN ?", soloOrgIDs, boardIDs)
+	if err := tx.Where("webhook_id IN (?)", hookIDs).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("organization_id IN ? OR board_id IN ?", soloOrgIDs, boardIDs).Delete(&models.Webhook{}).Error; err != nil {
+		return nil, err
+	}
+
+	// Yorumlar kullanıcıya bağlı olduğu için revizyon ve bildirimleriyle silinir
+	commentIDs := tx.Model(&models.Comment{}).Select("id").Where("user_id = ?", user.ID)
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.CommentRevision{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ? OR actor_id = ? OR comment_id IN (?)", user.ID, user.ID, commentIDs).
+		Delete(&models.Notification{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.Comment{}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ? OR actor_id = ?", user.ID, user.ID).Delete(&models.EmailNotification{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.NotificationPreference{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.TaskWatcher{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_assignees WHERE user_id = ?", user.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.ChecklistItem{}).Where("assignee_id = ?", user.ID).Update("assignee_id", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.BoardMember{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+		return nil, err
+	}
+
+	var soloOrgs []models.Organization
+	if err := tx.Where("id IN ?", soloOrgIDs).Find(&soloOrgs).Error; err != nil {
+		return nil, err
+	}
+	for _, org := range soloOrgs {
+		if err := tx.Delete(&org).Error; err != nil {
+			return nil, err
+		}
+		if err := recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "organization", EntityID: org.ID, OrgID: org.ID, Before: org,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Delete(&user).Error; err != nil {
+		return nil, err
+	}
+	return files, recordAudit(tx, c, auditEntry{
+		Action: models.AuditDelete, EntityType: "user", EntityID: user.ID, Before: user,
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GET /boards/:id/members - board üyelerini listele (tüm üyeler görebilir)
+func (h *BoardHandler) GetMembers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var members []models.BoardMember
+	if err := h.DB.Where("board_id = ?", board.ID).Preload("User").Order("id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// POST /boards/:id/members - kullanıcıyı board'a davet et (sadece owner)
+func (h *BoardHandler) AddMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var input struct {
+		UserID uint   `json:"user_id"`
+		Email  string `json:"email"`
+		Role   string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Role == "" {
+		input.Role = models.BoardRoleViewer
+	}
+	if !models.ValidBoardRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	// Davet edilen kullanıcı ID ya da email ile bulunur
+	var invitee models.User
+	query := h.DB.Where("id = ?", input.UserID)
+	if input.Email != "" {
+		query = h.DB.Where("email = ?", input.Email)
+	}
+	if err := query.First(&invitee).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Sadece aynı workspace'in üyeleri davet edilebilir
+	if _, err := orgRole(h.DB, board.OrganizationID, invitee.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this workspace"})
+		return
+	}
+
+	if _, err := boardRole(h.DB, board.ID, invitee.ID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	member := models.BoardMember{
+		BoardID: board.ID,
+		UserID:  invitee.ID,
+		Role:    input.Role,
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "board_member", EntityID: member.ID, BoardID: board.ID, After: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateUserCaches(h.Ctx, h.RDB, invitee.ID, board.OrganizationID)
+
+	member.User = &invitee
+	c.JSON(http.StatusCreated, gin.H{"data": member})
+}
+
+// PUT /boards/:id/members/:userId - üyenin rolünü değiştir (sadece owner)
+func (h *BoardHandler) UpdateMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var member models.BoardMember
+	if err := h.DB.Where("board_id = ? AND user_id = ?", board.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	var input struct {
+		Role string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || !models.ValidBoardRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	// Board'da en az bir owner kalmalı
+	if member.Role == models.BoardRoleOwner && input.Role != models.BoardRoleOwner && h.ownerCount(board.ID) <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Board must have at least one owner"})
+		return
+	}
+
+	before := member
+	member.Role = input.Role
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "board_member", EntityID: member.ID, BoardID: board.ID, Before: before, After: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	// AddMember/RemoveMember gibi rol değişikliğinde de üyelerin cache'i temizlenir
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// DELETE /boards/:id/members/:userId - üyeyi çıkar (owner) ya da board'dan ayrıl (kendisi)
+func (h *BoardHandler) RemoveMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var member models.BoardMember
+	if err := h.DB.Where("board_id = ? AND user_id = ?", board.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if member.UserID != userID {
+		if role, _ := boardRole(h.DB, board.ID, userID); role != models.BoardRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can remove members"})
+			return
+		}
+	}
+
+	if member.Role == models.BoardRoleOwner && h.ownerCount(board.ID) <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Board must have at least one owner"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		// Üye olmayan kullanıcı atanan ya da takipçi olarak kalmaz
+		if err := removeTaskLinks(tx, member.UserID, board.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "board_member", EntityID: member.ID, BoardID: board.ID, Before: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateUserCaches(h.Ctx, h.RDB, member.UserID, board.OrganizationID)
+	// Kalan üyelerin task listelerinde atanan olarak görünmesin
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+func (h *BoardHandler) ownerCount(boardID uint) int64 {
+	var count int64
+	h.DB.Model(&models.BoardMember{}).Where("board_id = ? AND role = ?", boardID, models.BoardRoleOwner).Count(&count)
+	return count
+}
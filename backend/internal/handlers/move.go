@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/rank"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rank'ler byte sırasıyla karşılaştırılır, DB collation'ından bağımsız olmalı
+const rankOrder = `rank COLLATE "C"`
+
+var errNeighbourNotInColumn = errors.New("neighbour task is not in the target column")
+
+// columnTasks kolondaki task'lar için sıralı sorgu
+func columnTasks(tx *gorm.DB, boardID uint, status string, excludeTaskID uint) *gorm.DB {
+	return tx.Model(&models.Task{}).
+		Where("board_id = ? AND status = ? AND id <> ?", boardID, status, excludeTaskID)
+}
+
+// rankAtEnd kolonun sonuna eklenecek task için rank
+func rankAtEnd(tx *gorm.DB, boardID uint, status string, excludeTaskID uint) (string, error) {
+	var last models.Task
+	err := columnTasks(tx, boardID, status, excludeTaskID).Order(rankOrder + " DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rank.Between("", "")
+	}
+	if err != nil {
+		return "", err
+	}
+	return rank.Between(last.Rank, "")
+}
+
+// rebalanceColumn kolondaki rank'leri eşit aralıklarla yeniden dağıtır.
+// İki komşu aynı rank'e sahipse (eski veriler) ya da aralarında rank kalmadıysa gerekir.
+func rebalanceColumn(tx *gorm.DB, boardID uint, status string, excludeTaskID uint) error {
+	var tasks []models.Task
+	if err := columnTasks(tx, boardID, status, excludeTaskID).Order(rankOrder + ", id").Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for i, r := range rank.Spread(len(tasks)) {
+		if err := tx.Model(&tasks[i]).UpdateColumn("rank", r).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rankBetweenNeighbours afterID'den sonra ya da beforeID'den önce gelecek rank'i hesaplar.
+// İkisi de verilmezse task kolonun sonuna eklenir.
+func rankBetweenNeighbours(tx *gorm.DB, boardID uint, status string, taskID, afterID, beforeID uint) (string, error) {
+	if afterID == 0 && beforeID == 0 {
+		return rankAtEnd(tx, boardID, status, taskID)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var prev, next string
+
+		if afterID != 0 {
+			var after models.Task
+			if err := columnTasks(tx, boardID, status, taskID).Where("id = ?", afterID).First(&after).Error; err != nil {
+				return "", errNeighbourNotInColumn
+			}
+			prev = after.Rank
+
+			var following models.Task
+			err := columnTasks(tx, boardID, status, taskID).
+				Where(rankOrder+" > ? COLLATE \"C\"", prev).
+				Order(rankOrder).First(&following).Error
+			if err == nil {
+				next = following.Rank
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", err
+			}
+		} else {
+			var before models.Task
+			if err := columnTasks(tx, boardID, status, taskID).Where("id = ?", beforeID).First(&before).Error; err != nil {
+				return "", errNeighbourNotInColumn
+			}
+			next = before.Rank
+
+			var preceding models.Task
+			err := columnTasks(tx, boardID, status, taskID).
+				Where(rankOrder+" < ? COLLATE \"C\"", next).
+				Order(rankOrder + " DESC").First(&preceding).Error
+			if err == nil {
+				prev = preceding.Rank
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", err
+			}
+		}
+
+		r, err := rank.Between(prev, next)
+		if err == nil {
+			return r, nil
+		}
+
+		// Komşular arasında boşluk yok, kolonu dengeleyip tekrar dene
+		if err := rebalanceColumn(tx, boardID, status, taskID); err != nil {
+			return "", err
+		}
+	}
+
+	return "", rank.ErrInvalidRange
+}
+
+// POST /tasks/:id/move - task'ı kolon/board içinde komşu task'a göre konumlandır
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+	before := task
+	oldBoardID := task.BoardID
+	oldStatus := task.Status
+
+	var input struct {
+		BoardID  uint   `json:"board_id"`
+		Status   string `json:"status"`
+		AfterID  uint   `json:"after_id"`  // bu task'ın hemen altına
+		BeforeID uint   `json:"before_id"` // ya da bu task'ın hemen üstüne
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || (input.AfterID != 0 && input.BeforeID != 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.BoardID != 0 && input.BoardID != task.BoardID {
+		if _, err := findBoardForUser(h.DB, input.BoardID, userID, orgID, models.BoardRoleEditor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Target board not found or access denied"})
+			return
+		}
+		task.BoardID = input.BoardID
+	}
+	if input.Status != "" {
+		task.Status = input.Status
+	}
+	// Status'u boş eski task'lar board'un ilk kolonuna alınır
+	if task.Status == "" {
+		task.Status = defaultStatus(h.DB, task.BoardID)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Aynı anda yapılan taşımalar aynı task üzerinde çakışmasın
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Task{}, task.ID).Error; err != nil {
+			return err
+		}
+
+		from := oldStatus
+		if task.BoardID != oldBoardID {
+			from = ""
+		}
+		if from == "" || task.Status != from {
+			if err := validateStatusChange(tx, task.BoardID, task.ID, from, task.Status); err != nil {
+				return err
+			}
+		}
+
+		r, err := rankBetweenNeighbours(tx, task.BoardID, task.Status, task.ID, input.AfterID, input.BeforeID)
+		if err != nil {
+			return err
+		}
+		task.Rank = r
+
+		if err := tx.Model(&task).Select("board_id", "status", "rank", "updated_at").Updates(&task).Error; err != nil {
+			return err
+		}
+		if task.BoardID != oldBoardID {
+			if err := pruneRelationsForBoard(tx, &task); err != nil {
+				return err
+			}
+			// Eski board'u dinleyenler de task'ın taşındığını görsün
+			if err := emitEvent(tx, c, auditEntry{
+				Action: models.AuditUpdate, EntityType: "task", EntityID: task.ID, BoardID: oldBoardID, TaskID: task.ID, After: task,
+			}); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "task", EntityID: task.ID, BoardID: task.BoardID, TaskID: task.ID, Before: before, After: task,
+		})
+	})
+	if errors.Is(err, errNeighbourNotInColumn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondTaskWriteError(c, err)
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, oldBoardID)
+	if task.BoardID != oldBoardID {
+		invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": task})
+}
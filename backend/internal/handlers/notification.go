@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Watch bildirimi üreten entity'ler
+var watchedEntities = []string{"task", "comment", "checklist_item", "attachment", "dependency"}
+
+type NotificationHandler struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+	Ctx context.Context
+}
+
+func NewNotificationHandler(db *gorm.DB, rdb *redis.Client) *NotificationHandler {
+	return &NotificationHandler{
+		DB:  db,
+		RDB: rdb,
+		Ctx: context.Background(),
+	}
+}
+
+// notificationEnabled userIDs içinden bildirim tipini kanalda kapatmamış olanları döner
+func notificationEnabled(db *gorm.DB, userIDs []uint, notificationType, channel string) ([]uint, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var disabled []uint
+	if err := db.Model(&models.NotificationPreference{}).
+		Where("user_id IN ? AND type = ? AND channel = ? AND NOT enabled", userIDs, notificationType, channel).
+		Pluck("user_id", &disabled).Error; err != nil {
+		return nil, err
+	}
+
+	enabled := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !slices.Contains(disabled, id) {
+			enabled = append(enabled, id)
+		}
+	}
+	return enabled, nil
+}
+
+// Bildirim tipinin kanalda gönderilip gönderilemeyeceği, ör. watch bildirimleri e-postayla gitmez
+func preferenceAllowed(notificationType, channel string) bool {
+	if !slices.Contains(models.NotificationTypes, notificationType) || !slices.Contains(models.NotificationChannels, channel) {
+		return false
+	}
+	return channel != models.ChannelEmail || slices.Contains(models.EmailNotificationTypes, notificationType)
+}
+
+// notify aynı bildirimi userIDs'deki kullanıcılara, açık oldukları kanallardan gönderir.
+// E-postalar EmailNotification olarak sıraya girer ve mail.Worker tarafından gönderilir.
+func notify(tx *gorm.DB, userIDs []uint, n models.Notification) error {
+	inApp, err := notificationEnabled(tx, userIDs, n.Type, models.ChannelInApp)
+	if err != nil {
+		return err
+	}
+	if len(inApp) > 0 {
+		notifications := make([]models.Notification, 0, len(inApp))
+		for _, id := range inApp {
+			n.UserID = id
+			notifications = append(notifications, n)
+		}
+		if err := tx.Create(&notifications).Error; err != nil {
+			return err
+		}
+	}
+
+	if !preferenceAllowed(n.Type, models.ChannelEmail) {
+		return nil
+	}
+	email, err := notificationEnabled(tx, userIDs, n.Type, models.ChannelEmail)
+	if err != nil || len(email) == 0 {
+		return err
+	}
+	emails := make([]models.EmailNotification, 0, len(email))
+	for _, id := range email {
+		emails = append(emails, models.EmailNotification{
+			UserID: id, ActorID: n.ActorID, Type: n.Type, TaskID: n.TaskID, CommentID: n.CommentID, Status: models.DeliveryPending,
+		})
+	}
+	return tx.Create(&emails).Error
+}
+
+// watchTask kullanıcıları task'ın izleyicisi yapar, zaten izleyenler atlanır
+func watchTask(tx *gorm.DB, taskID uint, userIDs ...uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	watchers := make([]models.TaskWatcher, 0, len(userIDs))
+	for _, id := range userIDs {
+		watchers = append(watchers, models.TaskWatcher{TaskID: taskID, UserID: id})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&watchers).Error
+}
+
+// notifyWatchers outbox event'i için task'ı izleyen board üyelerine bildirim yazar.
+// Değişikliği yapan ve aynı yorumda zaten bahsedilenler atlanır.
+func notifyWatchers(tx *gorm.DB, e models.OutboxEvent) error {
+	// Status değişimi task.updated ile zaten bildirilir
+	if e.BoardID == 0 || e.Type == webhook.TaskStatusChanged || !slices.Contains(watchedEntities, e.EntityType) {
+		return nil
+	}
+
+	var event struct {
+		TaskID  uint `json:"task_id"`
+		ActorID uint `json:"actor_id"`
+		Data    struct {
+			BoardID uint
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(e.Payload, &event); err != nil {
+		return err
+	}
+	if event.TaskID == 0 {
+		return nil
+	}
+	// Başka board'a taşınan task'ın eski board'a giden kopyası; izleyiciler yeni board'daki event'le bir kez bildirilir
+	if e.EntityType == "task" && event.Data.BoardID != 0 && event.Data.BoardID != e.BoardID {
+		return nil
+	}
+
+	query := tx.Model(&models.TaskWatcher{}).
+		Where("task_id = ? AND user_id <> ?", event.TaskID, event.ActorID).
+		Where("user_id IN (?)", tx.Model(&models.BoardMember{}).Select("user_id").Where("board_id = ?", e.BoardID))
+
+	n := models.Notification{ActorID: event.ActorID, Type: models.NotificationWatch, Event: e.Type, TaskID: event.TaskID}
+	if e.EntityType == "comment" {
+		n.CommentID = &e.EntityID
+		query = query.Where("user_id NOT IN (?)", tx.Model(&models.Notification{}).Select("user_id").
+			Where("comment_id = ? AND type = ?", e.EntityID, models.NotificationMention))
+	}
+
+	var userIDs []uint
+	if err := query.Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	return notify(tx, userIDs, n)
+}
+
+func (h *NotificationHandler) unreadCount(userID uint) (int64, error) {
+	var count int64
+	err := h.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// GET /notifications - bildirimler (yeniden eskiye), ?unread=true sadece okunmamışlar
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	query := h.DB.Where("user_id = ?", userID).Preload("Actor")
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := auditPage(query, c).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	unread, err := h.unreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notifications, "unread_count": unread})
+}
+
+// GET /notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	unread, err := h.unreadCount(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// POST /notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var notification models.Notification
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := h.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notification})
+}
+
+// POST /notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	result := h.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": result.RowsAffected, "unread_count": 0})
+}
+
+type notificationPreference struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+// GET /notifications/preferences - her tip ve kanal için tercih (kayıt yoksa açık) ve günlük özet ayarı
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var user models.User
+	if err := h.DB.Select("id", "email_digest").First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var saved []models.NotificationPreference
+	if err := h.DB.Where("user_id = ?", userID).Find(&saved).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	preferences := make([]notificationPreference, 0, len(models.NotificationTypes)*len(models.NotificationChannels))
+	for _, t := range models.NotificationTypes {
+		for _, channel := range models.NotificationChannels {
+			if !preferenceAllowed(t, channel) {
+				continue
+			}
+			p := notificationPreference{Type: t, Channel: channel, Enabled: true}
+			for _, s := range saved {
+				if s.Type == t && s.Channel == channel {
+					p.Enabled = s.Enabled
+				}
+			}
+			preferences = append(preferences, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences, "email_digest": user.EmailDigest})
+}
+
+// PUT /notifications/preferences - verilen tip/kanal tercihlerini ve günlük özet ayarını günceller, diğerleri değişmez
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var input struct {
+		Preferences []notificationPreference `json:"preferences"`
+		EmailDigest *bool                    `json:"email_digest"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || (len(input.Preferences) == 0 && input.EmailDigest == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	for _, p := range input.Preferences {
+		if !preferenceAllowed(p.Type, p.Channel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification type or channel"})
+			return
+		}
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for _, p := range input.Preferences {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			}).Create(&models.NotificationPreference{UserID: userID, Type: p.Type, Channel: p.Channel, Enabled: p.Enabled}).Error; err != nil {
+				return err
+			}
+		}
+		if input.EmailDigest != nil {
+			return tx.Model(&models.User{}).Where("id = ?", userID).Update("email_digest", *input.EmailDigest).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	h.GetPreferences(c)
+}
+
+// GET /tasks/:id/watchers - task'ı izleyen kullanıcılar
+func (h *TaskHandler) GetWatchers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var users []models.User
+	if err := h.DB.Where("id IN (?)", h.DB.Model(&models.TaskWatcher{}).Select("user_id").Where("task_id = ?", task.ID)).
+		Order("id").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": users, "watching": slices.ContainsFunc(users, func(u models.User) bool { return u.ID == userID })})
+}
+
+// POST /tasks/:id/watch - task'ı izlemeye başla (viewer dahil tüm üyeler)
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	if err := watchTask(h.DB, task.ID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watching": true})
+}
+
+// DELETE /tasks/:id/watch - task'ı izlemeyi bırak
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	if err := h.DB.Where("task_id = ? AND user_id = ?", task.ID, userID).Delete(&models.TaskWatcher{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watching": false})
+}
+
+// Bitiş tarihine bu kadar kala atananlara hatırlatma gider
+const dueSoonWindow = 24 * time.Hour
+
+// NotifyDueSoon bitişi yaklaşan açık task'ların atananlarına bir kez due_soon bildirimi gönderir
+func (h *TaskHandler) NotifyDueSoon() error {
+	now := time.Now()
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		var tasks []models.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("NOT tasks.due_notified AND tasks.due_at BETWEEN ? AND ?", now, now.Add(dueSoonWindow)).
+			Where(openTaskCondition).Order("tasks.due_at").Limit(100).Find(&tasks).Error; err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			var assignees []uint
+			// Board'dan çıkarılmış atananlara bildirim gitmez
+			if err := tx.Table("task_assignees").Where("task_id = ?", task.ID).
+				Where("user_id IN (?)", tx.Model(&models.BoardMember{}).Select("user_id").Where("board_id = ?", task.BoardID)).
+				Pluck("user_id", &assignees).Error; err != nil {
+				return err
+			}
+			if err := notify(tx, assignees, models.Notification{Type: models.NotificationDueSoon, TaskID: task.ID}); err != nil {
+				return err
+			}
+			// updated_at değişmesin
+			if err := tx.Model(&task).UpdateColumn("due_notified", true).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RunDueReminders yaklaşan bitiş tarihlerini interval aralıklarla kontrol eder, main'de goroutine olarak çalıştırılır
+func (h *TaskHandler) RunDueReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := h.NotifyDueSoon(); err != nil {
+			log.Println("due reminder error:", err)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type OrganizationHandler struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+	Ctx context.Context
+}
+
+func NewOrganizationHandler(db *gorm.DB, rdb *redis.Client) *OrganizationHandler {
+	return &OrganizationHandler{
+		DB:  db,
+		RDB: rdb,
+		Ctx: context.Background(),
+	}
+}
+
+// createOrganization workspace'i oluşturur ve ownerID'yi owner üye yapar
+func createOrganization(tx *gorm.DB, name string, ownerID uint) (models.Organization, error) {
+	org := models.Organization{Name: name}
+	if err := tx.Create(&org).Error; err != nil {
+		return org, err
+	}
+
+	err := tx.Create(&models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         ownerID,
+		Role:           models.OrgRoleOwner,
+	}).Error
+	return org, err
+}
+
+// createPersonalOrganization kayıt olan kullanıcı için kişisel workspace açar
+func createPersonalOrganization(tx *gorm.DB, user *models.User) error {
+	_, err := createOrganization(tx, user.Name+" Workspace", user.ID)
+	return err
+}
+
+// findOrganizationForUser workspace'i, kullanıcının en az required rolüne sahip olması şartıyla getirir
+func findOrganizationForUser(db *gorm.DB, orgID any, userID uint, required string) (models.Organization, error) {
+	var org models.Organization
+	if err := db.First(&org, orgID).Error; err != nil {
+		return org, err
+	}
+
+	role, err := orgRole(db, org.ID, userID)
+	if err != nil {
+		return org, err
+	}
+	if !models.OrgRoleAllows(role, required) {
+		return org, gorm.ErrRecordNotFound
+	}
+
+	return org, nil
+}
+
+// GET /organizations - kullanıcının üye olduğu workspace'ler
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var memberships []models.OrganizationMember
+	if err := h.DB.Where("user_id = ?", userID).Preload("Organization").Order("id").Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": memberships, "active_organization_id": c.GetUint("org_id")})
+}
+
+// POST /organizations - yeni workspace, oluşturan owner olur
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var org models.Organization
+	err := h.DB.Transaction(func(tx *gorm.DB) (err error) {
+		if org, err = createOrganization(tx, input.Name, userID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "organization", EntityID: org.ID, OrgID: org.ID, After: org,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": org})
+}
+
+// PUT /organizations/:id - workspace adını değiştir (admin ve owner)
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Name             string `json:"name"`
+		RequireTwoFactor *bool  `json:"require_two_factor"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || (input.Name == "" && input.RequireTwoFactor == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := org
+	if input.Name != "" {
+		org.Name = input.Name
+	}
+
+	if input.RequireTwoFactor != nil {
+		// Açan admin kendini workspace dışında bırakmasın
+		if *input.RequireTwoFactor && !org.RequireTwoFactor {
+			var count int64
+			h.DB.Model(&models.User{}).Where("id = ? AND totp_enabled_at IS NOT NULL", userID).Count(&count)
+			if count == 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Enable two-factor authentication on your account first"})
+				return
+			}
+		}
+		org.RequireTwoFactor = *input.RequireTwoFactor
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&org).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "organization", EntityID: org.ID, OrgID: org.ID, Before: before, After: org,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": org})
+}
+
+// POST /organizations/:id/switch - aktif workspace'i değiştirir, yeni access token döner
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	sessionID := c.GetString("session_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleMember)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if org.RequireTwoFactor && user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Organization requires two-factor authentication, enable it at /me/2fa"})
+		return
+	}
+
+	// Refresh sonrası da aynı workspace'te kalması için oturuma yaz
+	if err := auth.SetSessionOrganization(h.Ctx, h.RDB, sessionID, org.ID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked"})
+		return
+	}
+
+	tokenString, err := accessTokenFor(&user, &auth.Session{ID: sessionID, UserID: user.ID, OrganizationID: org.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      tokenString,
+		"expires_in": int(auth.AccessTokenTTL.Seconds()),
+		"data":       org,
+	})
+}
+
+// GET /organizations/:id/members
+func (h *OrganizationHandler) GetMembers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleMember)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var members []models.OrganizationMember
+	if err := h.DB.Where("organization_id = ?", org.ID).Preload("User").Order("id").Find(&members).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// POST /organizations/:id/members - kayıtlı kullanıcıyı workspace'e ekle (admin ve owner)
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleAdmin)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if input.Role == "" {
+		input.Role = models.OrgRoleMember
+	}
+	if !models.ValidOrgRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	// Owner atamayı sadece owner yapabilir
+	if input.Role == models.OrgRoleOwner {
+		if role, _ := orgRole(h.DB, org.ID, userID); role != models.OrgRoleOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can add owners"})
+			return
+		}
+	}
+
+	var invitee models.User
+	if err := h.DB.Where("email = ?", input.Email).First(&invitee).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if _, err := orgRole(h.DB, org.ID, invitee.ID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	member := models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         invitee.ID,
+		Role:           input.Role,
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "organization_member", EntityID: member.ID, OrgID: org.ID, After: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	h.RDB.Del(h.Ctx, usersCacheKey(org.ID))
+
+	member.User = &invitee
+	c.JSON(http.StatusCreated, gin.H{"data": member})
+}
+
+// PUT /organizations/:id/members/:userId - üyenin workspace rolünü değiştir (sadece owner)
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var member models.OrganizationMember
+	if err := h.DB.Where("organization_id = ? AND user_id = ?", org.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	var input struct {
+		Role string `json:"role"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || !models.ValidOrgRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	// Workspace'te en az bir owner kalmalı
+	if member.Role == models.OrgRoleOwner && input.Role != models.OrgRoleOwner && h.ownerCount(org.ID) <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization must have at least one owner"})
+		return
+	}
+
+	before := member
+	member.Role = input.Role
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "organization_member", EntityID: member.ID, OrgID: org.ID, Before: before, After: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": member})
+}
+
+// DELETE /organizations/:id/members/:userId - üyeyi çıkar (admin ve owner) ya da ayrıl (kendisi)
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	org, err := findOrganizationForUser(h.DB, c.Param("id"), userID, models.OrgRoleMember)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found or access denied"})
+		return
+	}
+
+	var member models.OrganizationMember
+	if err := h.DB.Where("organization_id = ? AND user_id = ?", org.ID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if member.UserID != userID {
+		role, _ := orgRole(h.DB, org.ID, userID)
+		if !models.OrgRoleAllows(role, models.OrgRoleAdmin) || !models.OrgRoleAllows(role, member.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			return
+		}
+	}
+
+	if member.Role == models.OrgRoleOwner && h.ownerCount(org.ID) <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Organization must have at least one owner"})
+		return
+	}
+
+	// Workspace'ten çıkan kullanıcı oradaki board'lara da erişimini kaybeder; her üyelik için
+	// board_member.deleted yazılır, böylece board'u dinleyen client'lar ve webhook'lar da haberdar olur
+	var boardMembers []models.BoardMember
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND board_id IN (?)", member.UserID,
+			tx.Model(&models.Board{}).Select("id").Where("organization_id = ?", org.ID)).
+			Find(&boardMembers).Error; err != nil {
+			return err
+		}
+		for _, boardMember := range boardMembers {
+			if err := tx.Delete(&boardMember).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(tx, c, auditEntry{
+				Action: models.AuditDelete, EntityType: "board_member", EntityID: boardMember.ID,
+				BoardID: boardMember.BoardID, OrgID: org.ID, Before: boardMember,
+			}); err != nil {
+				return err
+			}
+		}
+		// Çöp kutusundaki board'lar dahil workspace'teki task'larda atanan ve takipçi olarak da kalmaz
+		if err := removeTaskLinks(tx, member.UserID,
+			tx.Unscoped().Model(&models.Board{}).Select("id").Where("organization_id = ?", org.ID)); err != nil {
+			return err
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "organization_member", EntityID: member.ID, OrgID: org.ID, Before: member,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateUserCaches(h.Ctx, h.RDB, member.UserID, org.ID)
+	h.RDB.Del(h.Ctx, usersCacheKey(org.ID))
+	// Kalan üyelerin task listelerinde atanan olarak görünmesin
+	for _, boardMember := range boardMembers {
+		invalidateBoardCaches(h.Ctx, h.DB, h.RDB, boardMember.BoardID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+func (h *OrganizationHandler) ownerCount(orgID uint) int64 {
+	var count int64
+	h.DB.Model(&models.OrganizationMember{}).Where("organization_id = ? AND role = ?", orgID, models.OrgRoleOwner).Count(&count)
+	return count
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/outbox"
+	"github.com/ahmetcanc/TaskMan/internal/realtime"
+	"github.com/ahmetcanc/TaskMan/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Audit işlemlerinin event karşılığı
+var eventActions = map[string]string{
+	models.AuditCreate:  "created",
+	models.AuditUpdate:  "updated",
+	models.AuditDelete:  "deleted",
+	models.AuditRestore: "restored",
+}
+
+// emitEvent işlemin domain event'ini aynı transaction içinde outbox'a yazar; yayınlamayı relay yapar.
+// Task status'ü değiştiyse ayrıca task.status_changed yazılır.
+func emitEvent(tx *gorm.DB, c *gin.Context, e auditEntry) error {
+	action, ok := eventActions[e.Action]
+	if !ok || (e.Action == models.AuditUpdate && len(auditChanges(e.Before, e.After)) == 0) {
+		return nil
+	}
+	if e.ActorID == 0 {
+		e.ActorID = c.GetUint("user_id")
+	}
+	if e.OrgID == 0 {
+		e.OrgID = c.GetUint("org_id")
+	}
+	data := e.After
+	if data == nil {
+		data = e.Before
+	}
+
+	event := realtime.Event{
+		Type:     e.EntityType + "." + action,
+		BoardID:  e.BoardID,
+		OrgID:    e.OrgID,
+		TaskID:   e.TaskID,
+		EntityID: e.EntityID,
+		ActorID:  e.ActorID,
+		Data:     data,
+		At:       time.Now(),
+	}
+	if err := writeOutbox(tx, e, event); err != nil {
+		return err
+	}
+
+	if _, changed := auditChanges(e.Before, e.After)["Status"]; e.EntityType == "task" && e.Action == models.AuditUpdate && changed {
+		event.Type = webhook.TaskStatusChanged
+		return writeOutbox(tx, e, event)
+	}
+	return nil
+}
+
+func writeOutbox(tx *gorm.DB, e auditEntry, event realtime.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var txID int64
+	if err := tx.Raw("SELECT pg_current_xact_id()::text::bigint").Scan(&txID).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		TxID:           txID,
+		Type:           event.Type,
+		OrganizationID: e.OrgID,
+		BoardID:        e.BoardID,
+		EntityType:     e.EntityType,
+		EntityID:       e.EntityID,
+		Payload:        payload,
+		CreatedAt:      event.At,
+	}).Error
+}
+
+// publicEvent board'u dinleyen client'lara ve webhook'lara gidebilecek event'ler.
+// Webhook ayarları (URL'ler) board üyelerine gönderilmez.
+func publicEvent(e models.OutboxEvent) bool {
+	return e.BoardID != 0 && e.EntityType != "webhook"
+}
+
+// userEvent workspace üyeliği silinen kullanıcıya giden board'suz event; client'ları board erişimini yeniler
+func userEvent(e models.OutboxEvent) (realtime.Event, bool) {
+	if e.Type != "organization_member.deleted" {
+		return realtime.Event{}, false
+	}
+	var event realtime.Event
+	var member struct{ UserID uint }
+	if err := json.Unmarshal(e.Payload, &event); err != nil {
+		return event, false
+	}
+	if data, err := json.Marshal(event.Data); err != nil || json.Unmarshal(data, &member) != nil || member.UserID == 0 {
+		return event, false
+	}
+	event.UserID = member.UserID
+	return event, true
+}
+
+// OutboxConsumers relay'in çalıştırdığı consumer'lar: realtime yayın, webhook teslimatları, izleyici bildirimleri ve cache temizliği
+func OutboxConsumers(db *gorm.DB, rdb *redis.Client) []outbox.Consumer {
+	ctx := context.Background()
+
+	return []outbox.Consumer{
+		{
+			Name: "realtime",
+			Handle: func(tx *gorm.DB, e models.OutboxEvent) error {
+				if event, ok := userEvent(e); ok {
+					payload, err := json.Marshal(event)
+					if err != nil {
+						return err
+					}
+					return rdb.Publish(ctx, realtime.Channel, payload).Err()
+				}
+				if !publicEvent(e) {
+					return nil
+				}
+				return rdb.Publish(ctx, realtime.Channel, []byte(e.Payload)).Err()
+			},
+		},
+		{
+			// Teslimatlar offset ile aynı transaction'da oluşur, tekrar oluşmaz
+			Name: "webhooks",
+			Handle: func(tx *gorm.DB, e models.OutboxEvent) error {
+				if !publicEvent(e) {
+					return nil
+				}
+				var event realtime.Event
+				if err := json.Unmarshal(e.Payload, &event); err != nil {
+					return err
+				}
+				return enqueueWebhooks(tx, e.OrganizationID, event, e.Payload)
+			},
+		},
+		{
+			// Bildirimler offset ile aynı transaction'da yazılır
+			Name:   "notifications",
+			Handle: notifyWatchers,
+		},
+		{
+			Name: "cache",
+			Handle: func(tx *gorm.DB, e models.OutboxEvent) error {
+				invalidateEventCaches(ctx, db, rdb, e)
+				return nil
+			},
+		},
+	}
+}
+
+// invalidateEventCaches event'ten etkilenen board, task ve kullanıcı listesi cache'lerini temizler
+func invalidateEventCaches(ctx context.Context, db *gorm.DB, rdb *redis.Client, e models.OutboxEvent) {
+	if e.BoardID != 0 {
+		invalidateBoardCaches(ctx, db, rdb, e.BoardID)
+	}
+
+	switch e.EntityType {
+	case "board_member", "organization_member":
+		// Üyelikten çıkarılan kullanıcı artık board üyeleri arasında değil
+		var event struct{ Data struct{ UserID uint } }
+		if json.Unmarshal(e.Payload, &event) == nil {
+			invalidateUserCaches(ctx, rdb, event.Data.UserID, e.OrganizationID)
+		}
+		if e.EntityType == "organization_member" {
+			rdb.Del(ctx, usersCacheKey(e.OrganizationID))
+		}
+	case "user":
+		invalidateUsersCaches(ctx, db, rdb, e.EntityID)
+	}
+}
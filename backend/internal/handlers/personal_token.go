@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPersonalTokens       = 50
+	maxPersonalTokenExpDays = 365
+)
+
+// personalTokenScopes yetki listesini doğrular ve virgülle birleştirir; admin yetkisini sadece admin'ler alabilir
+func personalTokenScopes(scopes []string, role string) (string, bool) {
+	var unique []string
+	for _, s := range scopes {
+		if !models.ValidScope(s) || (s == models.ScopeAdmin && role != models.RoleAdmin) {
+			return "", false
+		}
+		if !containsString(unique, s) {
+			unique = append(unique, s)
+		}
+	}
+	return strings.Join(unique, ","), len(unique) > 0
+}
+
+// GET /me/tokens - kullanıcının kişisel API token'ları, token değerleri tekrar gösterilmez
+func (h *UserHandler) GetPersonalTokens(c *gin.Context) {
+	var tokens []models.PersonalAccessToken
+	if err := h.DB.Where("user_id = ?", c.GetUint("user_id")).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tokens, "scopes": models.Scopes})
+}
+
+// POST /me/tokens - aktif workspace için kişisel API token'ı oluşturur, token sadece bu cevapta döner
+func (h *UserHandler) CreatePersonalToken(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var input struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays *int     `json:"expires_in_days"` // boşsa süresiz
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required (max 100 characters)"})
+		return
+	}
+	scopes, ok := personalTokenScopes(input.Scopes, c.GetString("role"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scopes", "scopes": models.Scopes})
+		return
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresInDays != nil {
+		if *input.ExpiresInDays < 1 || *input.ExpiresInDays > maxPersonalTokenExpDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 1 and 365"})
+			return
+		}
+		t := time.Now().AddDate(0, 0, *input.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	var count int64
+	if err := h.DB.Model(&models.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if count >= maxPersonalTokens {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too many tokens, delete unused ones first"})
+		return
+	}
+
+	secret, hash, err := auth.NewPersonalToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	token := models.PersonalAccessToken{
+		UserID:         userID,
+		OrganizationID: c.GetUint("org_id"),
+		Name:           input.Name,
+		TokenHash:      hash,
+		Hint:           auth.PersonalTokenPrefix + "..." + secret[len(secret)-4:],
+		Scopes:         scopes,
+		ExpiresAt:      expiresAt,
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&token).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "personal_access_token", EntityID: token.ID, After: token,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": token, "token": secret})
+}
+
+// DELETE /me/tokens/:id - token'ı iptal eder
+func (h *UserHandler) DeletePersonalToken(c *gin.Context) {
+	var token models.PersonalAccessToken
+	if err := h.DB.Where("user_id = ?", c.GetUint("user_id")).First(&token, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&token).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "personal_access_token", EntityID: token.ID, Before: token,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token deleted"})
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/mail"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/oidc"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errSSONoAccount = errors.New("no account for sso email")
+
+// SSO girişini başlatan tarayıcıyı callback'e bağlayan cookie
+const ssoBrowserCookie = "taskman_sso"
+
+// setSSOCookie cookie sadece SSO endpoint'lerine gider ve JavaScript'ten okunamaz; maxAge < 0 siler
+func setSSOCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	secure := c.Request.TLS != nil || strings.HasPrefix(os.Getenv("APP_URL"), "https://")
+	c.SetCookie(ssoBrowserCookie, value, maxAge, "/auth/oidc", "", secure, true)
+}
+
+// ssoUserName yeni hesabın adı; IdP'de ad yoksa kullanıcı adı ya da e-postanın yerel kısmı
+func ssoUserName(claims *oidc.Claims) string {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.TrimSpace(claims.Username)
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name
+}
+
+// ssoUser IdP kimliğine bağlı kullanıcıyı döner. İlk girişte kimlik doğrulanmış e-postayla
+// mevcut hesaba bağlanır, hesap yoksa (AutoProvision açıksa) şifresiz yeni hesap açılır.
+func (h *UserHandler) ssoUser(c *gin.Context, claims *oidc.Claims) (models.User, error) {
+	var user models.User
+	issuer := h.OIDC.Config.Issuer
+	now := time.Now()
+
+	var identity models.UserIdentity
+	err := h.DB.Where("issuer = ? AND subject = ?", issuer, claims.Subject).First(&identity).Error
+	if err == nil {
+		if err := h.DB.First(&user, identity.UserID).Error; err != nil {
+			return user, err
+		}
+		h.DB.Model(&identity).UpdateColumns(map[string]any{"email": claims.Email, "last_login_at": now})
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+
+	err = h.DB.Where("LOWER(email) = LOWER(?)", claims.Email).First(&user).Error
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+	if !found && !h.OIDC.Config.AutoProvision {
+		return user, errSSONoAccount
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if !found {
+			// Şifresiz hesap; şifre gerekirse /password/forgot ile belirlenir
+			user = models.User{
+				Name:            ssoUserName(claims),
+				Email:           claims.Email,
+				Role:            models.RoleMember,
+				Language:        mail.DefaultLanguage,
+				EmailVerifiedAt: &now,
+			}
+			user.Role = verifiedUserRole(user)
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			if err := createPersonalOrganization(tx, &user); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, c, auditEntry{
+				ActorID: user.ID, Action: models.AuditCreate, EntityType: "user", EntityID: user.ID, After: user,
+			}); err != nil {
+				return err
+			}
+		} else if user.EmailVerifiedAt == nil {
+			// IdP e-postayı doğruladı. Doğrulanmamış hesabı adresin gerçek sahibi değil başkası
+			// açmış olabilir; onun şifresi, 2FA'sı, oturumları ve token'ları geçersiz kılınır.
+			before := user
+			user.EmailVerifiedAt = &now
+			user.Role = verifiedUserRole(user)
+			user.Password = ""
+			user.TOTPSecret = ""
+			user.TOTPEnabledAt = nil
+			if err := tx.Save(&user).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+				return err
+			}
+			if err := auth.RevokeUserSessions(h.Ctx, h.RDB, user.ID); err != nil {
+				return err
+			}
+			if err := recordAudit(tx, c, auditEntry{
+				ActorID: user.ID, Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user,
+			}); err != nil {
+				return err
+			}
+		}
+
+		identity = models.UserIdentity{
+			UserID:      user.ID,
+			Issuer:      issuer,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: &now,
+		}
+		if err := tx.Create(&identity).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			ActorID: user.ID, Action: models.AuditCreate, EntityType: "user_identity", EntityID: identity.ID, After: identity,
+		})
+	})
+	return user, err
+}
+
+// GET /auth/oidc/login - IdP giriş adresini döner, frontend kullanıcıyı oraya yönlendirir.
+// state ayrıca HttpOnly cookie ile bu tarayıcıya bağlanır; istek credentials ile yapılmalı.
+func (h *UserHandler) SSOLogin(c *gin.Context) {
+	if h.OIDC == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	var values [4]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+			return
+		}
+		values[i] = value
+	}
+	state, nonce, verifier, browser := values[0], values[1], values[2], values[3]
+
+	authURL, err := h.OIDC.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Println("oidc discovery error:", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+	if err := auth.SaveSSOState(h.Ctx, h.RDB, state, verifier, nonce, browser); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+	setSSOCookie(c, browser, int(auth.SSOStateTTL.Seconds()))
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authURL,
+		"state":             state,
+		"expires_in":        int(auth.SSOStateTTL.Seconds()),
+	})
+}
+
+// POST /auth/oidc/callback - IdP'nin redirect'indeki code ve state ile giriş, POST /login gibi token döner
+func (h *UserHandler) SSOCallback(c *gin.Context) {
+	if h.OIDC == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	var input struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	// state tek kullanımlık; aynı code ikinci kez denenemez. Girişi başlatan tarayıcının cookie'si
+	// olmadan state kabul edilmez, böylece saldırgan kendi code'uyla kurbanı kendi hesabına sokamaz.
+	browser, _ := c.Cookie(ssoBrowserCookie)
+	setSSOCookie(c, "", -1)
+	verifier, nonce, err := auth.ConsumeSSOState(h.Ctx, h.RDB, input.State, browser)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired state"})
+		return
+	}
+
+	claims, err := h.OIDC.Exchange(c.Request.Context(), input.Code, verifier, nonce)
+	if errors.Is(err, oidc.ErrEmailMissing) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider did not return an email"})
+		return
+	}
+	if err != nil {
+		log.Println("oidc exchange error:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed"})
+		return
+	}
+	// Doğrulanmamış e-postayla başkasının hesabına bağlanılamasın
+	if !claims.EmailVerified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email is not verified by the identity provider"})
+		return
+	}
+
+	user, err := h.ssoUser(c, claims)
+	if errors.Is(err, errSSONoAccount) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No account for this email"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	h.completeLogin(c, &user)
+}
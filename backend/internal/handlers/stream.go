@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Bağlantı canlılığı ve oturum iptali bu aralıkla kontrol edilir
+const streamPingInterval = 30 * time.Second
+
+// Token query'den geldiği için (cookie yok) origin kontrolüne gerek yok
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type StreamHandler struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+	Hub *realtime.Hub
+	Ctx context.Context
+}
+
+func NewStreamHandler(db *gorm.DB, rdb *redis.Client) *StreamHandler {
+	h := &StreamHandler{
+		DB:  db,
+		RDB: rdb,
+		Ctx: context.Background(),
+	}
+	h.Hub = realtime.NewHub(rdb, h.accessibleBoards)
+	return h
+}
+
+func (h *StreamHandler) accessibleBoards(userID, orgID uint) ([]uint, error) {
+	var ids []uint
+	err := memberBoardIDs(h.DB, userID, orgID).Pluck("board_members.board_id", &ids).Error
+	return ids, err
+}
+
+// streamMessage WebSocket üzerinden client'ın gönderebileceği mesaj
+type streamMessage struct {
+	Action string `json:"action"` // subscribe
+	Boards []uint `json:"boards"`
+}
+
+// parseBoardIDs "1,2,3" formatındaki board listesi
+func parseBoardIDs(value string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// GET /stream?boards=1,2 - board değişikliklerini canlı gönderir.
+// WebSocket upgrade isteği yoksa Server-Sent Events kullanılır. boards verilmezse erişilebilen tüm board'lar.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	boardIDs, err := parseBoardIDs(c.Query("boards"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid boards"})
+		return
+	}
+
+	client, err := h.Hub.Register(c.GetUint("user_id"), c.GetUint("org_id"), boardIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	defer h.Hub.Unregister(client)
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		h.serveWebSocket(c, client)
+		return
+	}
+	h.serveSSE(c, client)
+}
+
+// sessionActive uzun süren bağlantılarda logout / oturum ya da API token iptalini yakalar
+func (h *StreamHandler) sessionActive(c *gin.Context) bool {
+	if tokenID := c.GetUint("token_id"); tokenID != 0 {
+		var count int64
+		err := h.DB.Model(&models.PersonalAccessToken{}).
+			Where("id = ? AND (expires_at IS NULL OR expires_at > ?)", tokenID, time.Now()).Count(&count).Error
+		return err == nil && count > 0
+	}
+	active, err := auth.SessionActive(h.Ctx, h.RDB, c.GetString("session_id"))
+	return err == nil && active
+}
+
+func subscribedEvent(client *realtime.Client) realtime.Event {
+	return realtime.Event{Type: "subscribed", Data: gin.H{"boards": client.Boards()}, At: time.Now()}
+}
+
+func (h *StreamHandler) serveWebSocket(c *gin.Context, client *realtime.Client) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Println("websocket upgrade error:", err)
+		return
+	}
+	defer conn.Close()
+
+	// Okuma ayrı goroutine'de: subscribe mesajları ve bağlantı kapanışı
+	subscribed := make(chan realtime.Event, 1)
+	done := make(chan struct{})
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		defer close(done)
+		for {
+			var msg streamMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Action != "subscribe" {
+				continue
+			}
+			if err := h.Hub.Subscribe(client, msg.Boards); err != nil {
+				log.Println("realtime subscribe error:", err)
+				continue
+			}
+			select {
+			case subscribed <- subscribedEvent(client):
+			case <-closed:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	if err := conn.WriteJSON(subscribedEvent(client)); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case e, ok := <-client.Events:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case e := <-subscribed:
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if !h.sessionActive(c) {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked"))
+				return
+			}
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) serveSSE(c *gin.Context, client *realtime.Client) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx buffer'lamasın
+	c.Status(http.StatusOK)
+
+	write := func(e realtime.Event) bool {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	if !write(subscribedEvent(client)) {
+		return
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-client.Events:
+			if !ok || !write(e) {
+				return
+			}
+		case <-ticker.C:
+			if !h.sessionActive(c) {
+				return
+			}
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// openTaskCondition task'ın status'ü board'unun tamamlanmış kolonlarından biri değilse doğrudur
+const openTaskCondition = "tasks.status NOT IN (SELECT name FROM board_columns WHERE board_columns.board_id = tasks.board_id AND board_columns.done)"
+
+// openSubtaskIDs task'ın tamamlanmamış alt task'ları
+func openSubtaskIDs(db *gorm.DB, parentID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.Task{}).Where("tasks.parent_id = ?", parentID).Where(openTaskCondition).
+		Order("tasks.id").Pluck("tasks.id", &ids).Error
+	return ids, err
+}
+
+// validateParent parentID'nin aynı board'da olduğunu ve döngü oluşturmadığını kontrol eder
+func validateParent(db *gorm.DB, task *models.Task, parentID uint) error {
+	if parentID == task.ID {
+		return &fieldError{Message: "Task cannot be its own parent"}
+	}
+
+	var parent models.Task
+	if err := db.Where("id = ? AND board_id = ?", parentID, task.BoardID).First(&parent).Error; err != nil {
+		return &fieldError{Message: "Parent task must be on the same board"}
+	}
+
+	// Yeni task'ın alt task'ı olamaz, döngü kontrolü sadece mevcut task'lar için
+	if task.ID == 0 {
+		return nil
+	}
+	for seen := map[uint]bool{}; parent.ParentID != nil; {
+		if *parent.ParentID == task.ID {
+			return &fieldError{Message: "Parent would create a cycle"}
+		}
+		if seen[*parent.ParentID] {
+			break
+		}
+		seen[*parent.ParentID] = true
+		if err := db.First(&parent, *parent.ParentID).Error; err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// loadProgress task'ların checklist ve alt task ilerlemesini hesaplar
+func loadProgress(db *gorm.DB, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	type count struct {
+		TaskID uint
+		Done   int
+		Total  int
+	}
+
+	var items []count
+	if err := db.Model(&models.ChecklistItem{}).
+		Select("task_id, COUNT(*) FILTER (WHERE done) AS done, COUNT(*) AS total").
+		Where("task_id IN ?", ids).Group("task_id").Scan(&items).Error; err != nil {
+		return err
+	}
+
+	var subtasks []count
+	if err := db.Model(&models.Task{}).
+		Select("tasks.parent_id AS task_id, COUNT(*) FILTER (WHERE NOT ("+openTaskCondition+")) AS done, COUNT(*) AS total").
+		Where("tasks.parent_id IN ?", ids).Group("tasks.parent_id").Scan(&subtasks).Error; err != nil {
+		return err
+	}
+
+	progress := map[uint]*models.TaskProgress{}
+	for _, c := range append(items, subtasks...) {
+		p := progress[c.TaskID]
+		if p == nil {
+			p = &models.TaskProgress{}
+			progress[c.TaskID] = p
+		}
+		p.Done += c.Done
+		p.Total += c.Total
+	}
+
+	for i := range tasks {
+		if p := progress[tasks[i].ID]; p != nil {
+			p.Text = fmt.Sprintf("%d/%d done", p.Done, p.Total)
+			tasks[i].Progress = p
+		}
+	}
+	return nil
+}
+
+// GET /tasks/:id/subtasks - task'ın alt task'ları
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	task, err := findTaskForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
+		return
+	}
+
+	var subtasks []models.Task
+	if err := h.DB.Where("parent_id = ?", task.ID).Order(rankOrder).Preload("Assignees").Preload("Labels").
+		Find(&subtasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	if err := loadProgress(h.DB, subtasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": subtasks})
+}
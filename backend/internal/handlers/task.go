@@ -3,39 +3,45 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaskHandler struct {
-	DB  *gorm.DB
-	RDB *redis.Client
-	Ctx context.Context
+	DB      *gorm.DB
+	RDB     *redis.Client
+	Storage storage.Storage
+	Ctx     context.Context
 }
 
-func NewTaskHandler(db *gorm.DB, rdb *redis.Client) *TaskHandler {
+func NewTaskHandler(db *gorm.DB, rdb *redis.Client, store storage.Storage) *TaskHandler {
 	return &TaskHandler{
-		DB:  db,
-		RDB: rdb,
-		Ctx: context.Background(),
+		DB:      db,
+		RDB:     rdb,
+		Storage: store,
+		Ctx:     context.Background(),
 	}
 }
 
 // GET /tasks - Kullanıcının tüm task'ları
 func (h *TaskHandler) GetTasks(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 
-	// User-specific cache
-	cacheKey := fmt.Sprintf("tasks_user_%d", userID)
+	// Filtre/sort yoksa user-specific cache kullanılır
+	useCache := c.Request.URL.RawQuery == ""
+	cacheKey := tasksCacheKey(userID, orgID)
 	cached, err := h.RDB.Get(h.Ctx, cacheKey).Result()
-	if err == nil && cached != "" {
+	if useCache && err == nil && cached != "" {
 		var tasks []models.Task
 		if err := json.Unmarshal([]byte(cached), &tasks); err == nil {
 			c.JSON(http.StatusOK, gin.H{"data": tasks, "source": "cache"})
@@ -43,17 +49,27 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		}
 	}
 
+	// Task'ları kullanıcının üye olduğu board'lara göre filtrele
+	query, err := applyTaskFilters(h.DB.Where("tasks.board_id IN (?)", memberBoardIDs(h.DB, userID, orgID)), c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var tasks []models.Task
-	// Task'ları board üzerinden user'a göre filtrele
-	if err := h.DB.Joins("JOIN boards ON tasks.board_id = boards.id").
-		Where("boards.user_id = ?", userID).
-		Find(&tasks).Error; err != nil {
+	if err := query.Preload("Assignees").Preload("Labels").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if err := loadProgress(h.DB, tasks); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
-	data, _ := json.Marshal(tasks)
-	h.RDB.Set(h.Ctx, cacheKey, data, time.Hour)
+	if useCache {
+		data, _ := json.Marshal(tasks)
+		h.RDB.Set(h.Ctx, cacheKey, data, time.Hour)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": tasks, "source": "db"})
 }
@@ -61,16 +77,29 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 // GET /tasks/:id - Tek task getir
 func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 	id := c.Param("id")
 
-	var task models.Task
-	// Task'ın kullanıcının board'una ait olup olmadığını kontrol et
-	if err := h.DB.Joins("JOIN boards ON tasks.board_id = boards.id").
-		Where("tasks.id = ? AND boards.user_id = ?", id, userID).
-		First(&task).Error; err != nil {
+	// Task'ın board'unda en az viewer olmalı
+	task, err := findTaskForUser(h.DB, id, userID, orgID, models.BoardRoleViewer)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
 		return
 	}
+	h.DB.Model(&task).Association("Assignees").Find(&task.Assignees)
+	h.DB.Model(&task).Association("Labels").Find(&task.Labels)
+
+	tasks := []models.Task{task}
+	if err := loadProgress(h.DB, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	task = tasks[0]
+
+	if err := loadDependencies(h.DB, &task, userID, orgID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": task})
 }
@@ -78,12 +107,14 @@ func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 // POST /tasks - Yeni task oluştur
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 
 	var input struct {
 		Title       string `json:"title"`
 		Description string `json:"description"`
 		BoardID     uint   `json:"board_id"`
 		Status      string `json:"status"`
+		taskFieldsInput
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -91,30 +122,62 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Board'un kullanıcıya ait olup olmadığını kontrol et
-	var board models.Board
-	if err := h.DB.Where("id = ? AND user_id = ?", input.BoardID, userID).First(&board).Error; err != nil {
+	// Task eklemek için board'da en az editor olmalı
+	if _, err := findBoardForUser(h.DB, input.BoardID, userID, orgID, models.BoardRoleEditor); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Board not found or access denied"})
 		return
 	}
 
+	// Status verilmezse board'un ilk kolonu
+	if input.Status == "" {
+		input.Status = defaultStatus(h.DB, input.BoardID)
+	}
+
 	task := models.Task{
 		Title:       input.Title,
 		Description: input.Description,
 		Status:      input.Status,
+		Priority:    models.PriorityMedium,
 		BoardID:     input.BoardID,
 	}
 
-	if err := h.DB.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+	relations, err := input.apply(h.DB, &task)
+	if err != nil {
+		respondTaskWriteError(c, err)
 		return
 	}
 
-	// User-specific cache'leri temizle
-	userBoardsCacheKey := fmt.Sprintf("boards_user_%d", userID)
-	userTasksCacheKey := fmt.Sprintf("tasks_user_%d", userID)
-	h.RDB.Del(h.Ctx, userBoardsCacheKey)
-	h.RDB.Del(h.Ctx, userTasksCacheKey)
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := validateStatusChange(tx, task.BoardID, 0, "", task.Status); err != nil {
+			return err
+		}
+		// Yeni task kolonun sonuna eklenir
+		r, err := rankAtEnd(tx, task.BoardID, task.Status, 0)
+		if err != nil {
+			return err
+		}
+		task.Rank = r
+		if err := tx.Omit(clause.Associations).Create(&task).Error; err != nil {
+			return err
+		}
+		if err := relations.save(tx, &task, userID); err != nil {
+			return err
+		}
+		// Oluşturan task'ı otomatik izler
+		if err := watchTask(tx, task.ID, userID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "task", EntityID: task.ID, BoardID: task.BoardID, TaskID: task.ID, After: task,
+		})
+	})
+	if err != nil {
+		respondTaskWriteError(c, err)
+		return
+	}
+
+	// Board üyelerinin cache'lerini temizle
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
 
 	c.JSON(http.StatusCreated, gin.H{"data": task})
 }
@@ -122,6 +185,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // PUT /tasks/:id - Task güncelle
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -129,20 +193,22 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	var task models.Task
-	// Task'ın kullanıcının board'una ait olup olmadığını kontrol et
-	if err := h.DB.Joins("JOIN boards ON tasks.board_id = boards.id").
-		Where("tasks.id = ? AND boards.user_id = ?", id, userID).
-		First(&task).Error; err != nil {
+	// Task'ı güncellemek için board'da en az editor olmalı
+	task, err := findTaskForUser(h.DB, id, userID, orgID, models.BoardRoleEditor)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
 		return
 	}
+	before := task
+	oldBoardID := task.BoardID
+	oldStatus := task.Status
 
 	var input struct {
 		Title       string `json:"title"`
 		Description string `json:"description"`
 		BoardID     uint   `json:"board_id"`
 		Status      string `json:"status"`
+		taskFieldsInput
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -150,32 +216,80 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	// Eğer board_id değiştiriliyorsa, yeni board'un da kullanıcıya ait olduğunu kontrol et
+	// Eğer board_id değiştiriliyorsa, yeni board'da da en az editor olmalı
 	if input.BoardID != 0 && input.BoardID != task.BoardID {
-		var newBoard models.Board
-		if err := h.DB.Where("id = ? AND user_id = ?", input.BoardID, userID).First(&newBoard).Error; err != nil {
+		if _, err := findBoardForUser(h.DB, input.BoardID, userID, orgID, models.BoardRoleEditor); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Target board not found or access denied"})
 			return
 		}
 		task.BoardID = input.BoardID
 	}
 
-	// Task'ı güncelle
+	// Task'ı güncelle - status boş gelirse mevcut status korunur
 	task.Title = input.Title
 	task.Description = input.Description
-	task.Status = input.Status
+	if input.Status != "" {
+		task.Status = input.Status
+	}
+	// Status'u boş eski task'lar board'un ilk kolonuna alınır
+	if task.Status == "" {
+		task.Status = defaultStatus(h.DB, task.BoardID)
+	}
 
-	if err := h.DB.Save(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+	relations, err := input.apply(h.DB, &task)
+	if err != nil {
+		respondTaskWriteError(c, err)
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Board değiştiyse eski kolondan geçiş kuralı uygulanmaz, sadece yeni board'un kolonları geçerli
+		from := oldStatus
+		if task.BoardID != oldBoardID {
+			from = ""
+		}
+		if from == "" || task.Status != from {
+			if err := validateStatusChange(tx, task.BoardID, task.ID, from, task.Status); err != nil {
+				return err
+			}
+			// Kolon değiştiyse yeni kolonun sonuna eklenir
+			r, err := rankAtEnd(tx, task.BoardID, task.Status, task.ID)
+			if err != nil {
+				return err
+			}
+			task.Rank = r
+		}
+		if err := tx.Omit(clause.Associations).Save(&task).Error; err != nil {
+			return err
+		}
+		if err := relations.save(tx, &task, userID); err != nil {
+			return err
+		}
+		if task.BoardID != oldBoardID {
+			if err := pruneRelationsForBoard(tx, &task); err != nil {
+				return err
+			}
+			// Eski board'u dinleyenler de task'ın taşındığını görsün
+			if err := emitEvent(tx, c, auditEntry{
+				Action: models.AuditUpdate, EntityType: "task", EntityID: task.ID, BoardID: oldBoardID, TaskID: task.ID, After: task,
+			}); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "task", EntityID: task.ID, BoardID: task.BoardID, TaskID: task.ID, Before: before, After: task,
+		})
+	})
+	if err != nil {
+		respondTaskWriteError(c, err)
 		return
 	}
 
-	// User-specific cache'leri temizle
-	userBoardsCacheKey := fmt.Sprintf("boards_user_%d", userID)
-	userTasksCacheKey := fmt.Sprintf("tasks_user_%d", userID)
-	h.RDB.Del(h.Ctx, userBoardsCacheKey)
-	h.RDB.Del(h.Ctx, userTasksCacheKey)
-	h.RDB.Del(h.Ctx, fmt.Sprintf("task_user_%d_%d", userID, id))
+	// Eski ve yeni board üyelerinin cache'lerini temizle
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, oldBoardID)
+	if task.BoardID != oldBoardID {
+		invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": task})
 }
@@ -183,28 +297,259 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 // DELETE /tasks/:id - Task sil
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
 	id := c.Param("id")
 
-	var task models.Task
-	// Task'ın kullanıcının board'una ait olup olmadığını kontrol et
-	if err := h.DB.Joins("JOIN boards ON tasks.board_id = boards.id").
-		Where("tasks.id = ? AND boards.user_id = ?", id, userID).
-		First(&task).Error; err != nil {
+	// Task silmek için board'da en az editor olmalı
+	task, err := findTaskForUser(h.DB, id, userID, orgID, models.BoardRoleEditor)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found or access denied"})
 		return
 	}
 
-	if err := h.DB.Delete(&task).Error; err != nil {
+	// Task alt task'larıyla birlikte (aynı silinme zamanıyla) çöp kutusuna taşınır, ilişkileri geri yükleme için korunur
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		ids, err := subtreeTaskIDs(tx, task.ID, gorm.DeletedAt{})
+		if err != nil {
+			return err
+		}
+		tasks := []models.Task{task}
+		if len(ids) > 0 {
+			var subtasks []models.Task
+			if err := tx.Where("id IN ?", ids).Order("id").Find(&subtasks).Error; err != nil {
+				return err
+			}
+			tasks = append(tasks, subtasks...)
+		}
+
+		if err := tx.Where("id IN ?", append(ids, task.ID)).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if err := recordAudit(tx, c, auditEntry{
+				Action: models.AuditDelete, EntityType: "task", EntityID: t.ID, BoardID: t.BoardID, TaskID: t.ID, Before: t,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
-	// User-specific cache'leri temizle
-	userBoardsCacheKey := fmt.Sprintf("boards_user_%d", userID)
-	userTasksCacheKey := fmt.Sprintf("tasks_user_%d", userID)
-	h.RDB.Del(h.Ctx, userBoardsCacheKey)
-	h.RDB.Del(h.Ctx, userTasksCacheKey)
-	h.RDB.Del(h.Ctx, fmt.Sprintf("task_user_%d_%s", userID, id))
+	// Board üyelerinin cache'lerini temizle
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted"})
 }
+
+// optionalTime JSON'da alan gönderilmediyse Set false olur; null gönderilirse değer temizlenir
+type optionalTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (o *optionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	o.Value = &t
+	return nil
+}
+
+// optionalID optionalTime gibi; null gönderilirse ilişki kaldırılır
+type optionalID struct {
+	Set   bool
+	Value *uint
+}
+
+func (o *optionalID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// taskFieldsInput POST/PUT /tasks için atanan, etiket, üst task, tarih ve öncelik alanları.
+// Gönderilmeyen alanlar güncellemede olduğu gibi kalır.
+type taskFieldsInput struct {
+	Priority    string       `json:"priority"`
+	StartAt     optionalTime `json:"start_at"`
+	DueAt       optionalTime `json:"due_at"`
+	AssigneeIDs *[]uint      `json:"assignee_ids"`
+	LabelIDs    *[]uint      `json:"label_ids"`
+	ParentID    optionalID   `json:"parent_id"`
+}
+
+// fieldError task alanlarında geçersiz değer (400)
+type fieldError struct {
+	Message string
+}
+
+func (e *fieldError) Error() string {
+	return e.Message
+}
+
+// taskRelations yazılacak many2many ilişkiler, nil olanlar değişmez
+type taskRelations struct {
+	Assignees []models.User
+	Labels    []models.Label
+}
+
+func uniqueCount(ids []uint) int {
+	unique := map[uint]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return len(unique)
+}
+
+// apply alanları doğrulayıp task'a yazar, değişecek ilişkileri döner
+func (in *taskFieldsInput) apply(db *gorm.DB, task *models.Task) (*taskRelations, error) {
+	if in.Priority != "" {
+		if !models.ValidPriority(in.Priority) {
+			return nil, &fieldError{Message: "Invalid priority"}
+		}
+		task.Priority = in.Priority
+	}
+	if in.StartAt.Set {
+		task.StartAt = in.StartAt.Value
+	}
+	if in.DueAt.Set {
+		task.DueAt = in.DueAt.Value
+		// Yeni bitiş tarihi için hatırlatma tekrar gönderilir
+		task.DueNotified = false
+	}
+	if task.StartAt != nil && task.DueAt != nil && task.StartAt.After(*task.DueAt) {
+		return nil, &fieldError{Message: "start_at must be before due_at"}
+	}
+
+	if in.ParentID.Set {
+		if in.ParentID.Value != nil {
+			if err := validateParent(db, task, *in.ParentID.Value); err != nil {
+				return nil, err
+			}
+		}
+		task.ParentID = in.ParentID.Value
+	}
+
+	relations := &taskRelations{}
+
+	// Sadece board'a erişimi olan kullanıcılar atanabilir
+	if in.AssigneeIDs != nil {
+		relations.Assignees = []models.User{}
+		if len(*in.AssigneeIDs) > 0 {
+			if err := db.Where("id IN ? AND id IN (?)", *in.AssigneeIDs,
+				db.Model(&models.BoardMember{}).Select("user_id").Where("board_id = ?", task.BoardID)).
+				Find(&relations.Assignees).Error; err != nil {
+				return nil, err
+			}
+		}
+		if len(relations.Assignees) != uniqueCount(*in.AssigneeIDs) {
+			return nil, &fieldError{Message: "Assignees must be members of the board"}
+		}
+	}
+
+	// Sadece task'ın board'una ait etiketler eklenebilir
+	if in.LabelIDs != nil {
+		relations.Labels = []models.Label{}
+		if len(*in.LabelIDs) > 0 {
+			if err := db.Where("id IN ? AND board_id = ?", *in.LabelIDs, task.BoardID).
+				Find(&relations.Labels).Error; err != nil {
+				return nil, err
+			}
+		}
+		if len(relations.Labels) != uniqueCount(*in.LabelIDs) {
+			return nil, &fieldError{Message: "Labels must belong to the task's board"}
+		}
+	}
+
+	return relations, nil
+}
+
+// save ilişkileri yazar; yeni atananlar bildirim alır ve task'ı izlemeye başlar
+func (r *taskRelations) save(tx *gorm.DB, task *models.Task, actorID uint) error {
+	if r.Assignees != nil {
+		var previous []uint
+		if err := tx.Table("task_assignees").Where("task_id = ?", task.ID).Pluck("user_id", &previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_assignees WHERE task_id = ?", task.ID).Error; err != nil {
+			return err
+		}
+		for _, user := range r.Assignees {
+			if err := tx.Exec("INSERT INTO task_assignees (task_id, user_id) VALUES (?, ?)", task.ID, user.ID).Error; err != nil {
+				return err
+			}
+		}
+		task.Assignees = r.Assignees
+
+		var added, notified []uint
+		for _, user := range r.Assignees {
+			if slices.Contains(previous, user.ID) {
+				continue
+			}
+			added = append(added, user.ID)
+			if user.ID != actorID {
+				notified = append(notified, user.ID)
+			}
+		}
+		if err := watchTask(tx, task.ID, added...); err != nil {
+			return err
+		}
+		if err := notify(tx, notified, models.Notification{
+			ActorID: actorID,
+			Type:    models.NotificationAssigned,
+			TaskID:  task.ID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if r.Labels != nil {
+		if err := tx.Exec("DELETE FROM task_labels WHERE task_id = ?", task.ID).Error; err != nil {
+			return err
+		}
+		for _, label := range r.Labels {
+			if err := tx.Exec("INSERT INTO task_labels (task_id, label_id) VALUES (?, ?)", task.ID, label.ID).Error; err != nil {
+				return err
+			}
+		}
+		task.Labels = r.Labels
+	}
+
+	return nil
+}
+
+// pruneRelationsForBoard task başka board'a taşındığında yeni board'da geçerli olmayan
+// atanan, etiket ve üst/alt task ilişkilerini kaldırır
+func pruneRelationsForBoard(tx *gorm.DB, task *models.Task) error {
+	if err := tx.Exec(`DELETE FROM task_assignees WHERE task_id = ?
+		AND user_id NOT IN (SELECT user_id FROM board_members WHERE board_id = ?)`, task.ID, task.BoardID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`DELETE FROM task_labels WHERE task_id = ?
+		AND label_id NOT IN (SELECT id FROM labels WHERE board_id = ?)`, task.ID, task.BoardID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`UPDATE checklist_items SET assignee_id = NULL WHERE task_id = ?
+		AND assignee_id NOT IN (SELECT user_id FROM board_members WHERE board_id = ?)`, task.ID, task.BoardID).Error; err != nil {
+		return err
+	}
+
+	// Alt task ilişkisi board'lar arası olamaz
+	if err := tx.Exec(`UPDATE tasks SET parent_id = NULL WHERE parent_id = ? AND board_id <> ?`, task.ID, task.BoardID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`UPDATE tasks SET parent_id = NULL WHERE id = ?
+		AND parent_id NOT IN (SELECT id FROM tasks WHERE board_id = ?)`, task.ID, task.BoardID).Error; err != nil {
+		return err
+	}
+	return tx.Select("parent_id").Take(task).Error
+}
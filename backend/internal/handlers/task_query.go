@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// En acil öncelik önce gelir: sort=priority → urgent, high, medium, low
+const priorityOrder = `CASE tasks.priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END`
+
+// GET /tasks için izin verilen sort alanları
+var taskSortColumns = map[string]string{
+	"rank":       "tasks." + rankOrder,
+	"priority":   priorityOrder,
+	"due_at":     "tasks.due_at",
+	"start_at":   "tasks.start_at",
+	"created_at": "tasks.created_at",
+	"updated_at": "tasks.updated_at",
+	"title":      "tasks.title",
+}
+
+// parseTime RFC3339 ya da YYYY-MM-DD formatını kabul eder
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyTaskFilters GET /tasks query parametrelerini sorguya uygular:
+// assignee (id, "me", "none"), status, priority, label, board_id, parent_id (id, "none"),
+// due_before, due_after, sort (- ile azalan)
+func applyTaskFilters(query *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	if assignee := c.Query("assignee"); assignee != "" {
+		switch assignee {
+		case "none":
+			query = query.Where("tasks.id NOT IN (SELECT task_id FROM task_assignees)")
+		default:
+			assigneeID := c.GetUint("user_id")
+			if assignee != "me" {
+				id, err := strconv.ParseUint(assignee, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid assignee")
+				}
+				assigneeID = uint(id)
+			}
+			query = query.Where("tasks.id IN (SELECT task_id FROM task_assignees WHERE user_id = ?)", assigneeID)
+		}
+	}
+
+	if statuses := splitList(c.Query("status")); len(statuses) > 0 {
+		query = query.Where("tasks.status IN ?", statuses)
+	}
+
+	if priorities := splitList(c.Query("priority")); len(priorities) > 0 {
+		for _, p := range priorities {
+			if !models.ValidPriority(p) {
+				return nil, fmt.Errorf("invalid priority")
+			}
+		}
+		query = query.Where("tasks.priority IN ?", priorities)
+	}
+
+	// label=1,2 verilen etiketlerden en az birine sahip task'lar
+	if labels := splitList(c.Query("label")); len(labels) > 0 {
+		labelIDs := make([]uint64, 0, len(labels))
+		for _, l := range labels {
+			id, err := strconv.ParseUint(l, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid label")
+			}
+			labelIDs = append(labelIDs, id)
+		}
+		query = query.Where("tasks.id IN (SELECT task_id FROM task_labels WHERE label_id IN ?)", labelIDs)
+	}
+
+	if boardID := c.Query("board_id"); boardID != "" {
+		id, err := strconv.ParseUint(boardID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid board_id")
+		}
+		query = query.Where("tasks.board_id = ?", id)
+	}
+
+	if parentID := c.Query("parent_id"); parentID != "" {
+		if parentID == "none" {
+			query = query.Where("tasks.parent_id IS NULL")
+		} else {
+			id, err := strconv.ParseUint(parentID, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parent_id")
+			}
+			query = query.Where("tasks.parent_id = ?", id)
+		}
+	}
+
+	if dueBefore := c.Query("due_before"); dueBefore != "" {
+		t, err := parseTime(dueBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_before")
+		}
+		query = query.Where("tasks.due_at < ?", t)
+	}
+
+	if dueAfter := c.Query("due_after"); dueAfter != "" {
+		t, err := parseTime(dueAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_after")
+		}
+		query = query.Where("tasks.due_at > ?", t)
+	}
+
+	sort := c.DefaultQuery("sort", "rank")
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		sort = sort[1:]
+		direction = "DESC"
+	}
+	column, ok := taskSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort")
+	}
+	if sort == "rank" {
+		// Board içi sıra; yön hem board'a hem rank'e uygulanır
+		return query.Order("tasks.board_id " + direction).
+			Order(column + " " + direction).
+			Order("tasks.id " + direction), nil
+	}
+
+	return query.Order(column + " " + direction + " NULLS LAST").Order("tasks.id"), nil
+}
@@ -0,0 +1,443 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Çöp kutusundaki kayıtların kalıcı silinmeden önce tutulduğu varsayılan süre
+const defaultTrashRetentionDays = 30
+
+// Birden fazla instance aynı anda temizlik yapmasın
+const trashPurgeLockKey = "trash_purge_lock"
+
+type TrashHandler struct {
+	DB      *gorm.DB
+	RDB     *redis.Client
+	Storage storage.Storage
+	Ctx     context.Context
+}
+
+func NewTrashHandler(db *gorm.DB, rdb *redis.Client, store storage.Storage) *TrashHandler {
+	return &TrashHandler{
+		DB:      db,
+		RDB:     rdb,
+		Storage: store,
+		Ctx:     context.Background(),
+	}
+}
+
+// trashRetention TRASH_RETENTION_DAYS ile değiştirilebilir
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// purgeTasks task'ları atanan, etiket, yorum, checklist, bağımlılık ve ek kayıtlarıyla birlikte kalıcı olarak siler.
+// Silinen eklerin storage key'lerini döner, dosyalar commit sonrası kaldırılmalı.
+func purgeTasks(tx *gorm.DB, taskIDs []uint) ([]string, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	var files []string
+	if err := tx.Model(&models.Attachment{}).Where("task_id IN ?", taskIDs).Pluck("storage_key", &files).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_assignees WHERE task_id IN ?", taskIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_labels WHERE task_id IN ?", taskIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := deleteTaskComments(tx, taskIDs); err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.ChecklistItem{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskWatcher{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("blocker_id IN ? OR blocked_id IN ?", taskIDs, taskIDs).Delete(&models.TaskDependency{}).Error; err != nil {
+		return nil, err
+	}
+	// Silinen task'ların alt task'ları üst seviyeye çıkar
+	if err := tx.Unscoped().Model(&models.Task{}).Where("parent_id IN ?", taskIDs).Update("parent_id", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Task{}, taskIDs).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// subtreeTaskIDs task'ın tüm seviyelerdeki alt task'ları; deletedAt geçerliyse o anda silinmiş olanlar, değilse silinmemişler
+func subtreeTaskIDs(tx *gorm.DB, taskID uint, deletedAt gorm.DeletedAt) ([]uint, error) {
+	cond, args := "t.deleted_at IS NULL", []any{taskID}
+	if deletedAt.Valid {
+		cond = "t.deleted_at = ?"
+		args = append(args, deletedAt.Time)
+	}
+	args = append(args, args[1:]...)
+
+	var ids []uint
+	err := tx.Raw(`WITH RECURSIVE subtree(id) AS (
+			SELECT t.id FROM tasks t WHERE t.parent_id = ? AND `+cond+`
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree ON t.parent_id = subtree.id WHERE `+cond+`
+		)
+		SELECT id FROM subtree`, args...).Scan(&ids).Error
+	return ids, err
+}
+
+// purgeBoard board'u task'ları, üyelikleri, kolonları ve etiketleriyle birlikte kalıcı olarak siler
+func purgeBoard(tx *gorm.DB, boardID uint) ([]string, error) {
+	var taskIDs []uint
+	if err := tx.Unscoped().Model(&models.Task{}).Where("board_id = ?", boardID).Pluck("id", &taskIDs).Error; err != nil {
+		return nil, err
+	}
+	files, err := purgeTasks(tx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("board_id = ?", boardID).Delete(&models.BoardMember{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("board_id = ?", boardID).Delete(&models.ColumnTransition{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("board_id = ?", boardID).Delete(&models.BoardColumn{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_labels WHERE label_id IN (SELECT id FROM labels WHERE board_id = ?)", boardID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("board_id = ?", boardID).Delete(&models.Label{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Board{}, boardID).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// GET /trash - aktif workspace'te silinmiş board'lar (owner olunanlar) ve task'lar
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	var boards []models.Board
+	if err := h.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND organization_id = ?", orgID).
+		Where("id IN (?)", h.DB.Model(&models.BoardMember{}).Select("board_id").
+			Where("user_id = ? AND role = ?", userID, models.BoardRoleOwner)).
+		Order("deleted_at DESC").Find(&boards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	// Board'u ya da üst task'ı ile birlikte silinen task'lar onlarla geri yüklenir, burada sadece tek başına silinenler listelenir
+	var tasks []models.Task
+	if err := h.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND board_id IN (?)", memberBoardIDs(h.DB, userID, orgID)).
+		Where("NOT EXISTS (SELECT 1 FROM tasks p WHERE p.id = tasks.parent_id AND p.deleted_at = tasks.deleted_at)").
+		Order("deleted_at DESC").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"boards":         boards,
+		"tasks":          tasks,
+		"retention_days": int(trashRetention().Hours() / 24),
+	})
+}
+
+// POST /trash/:type/:id/restore - board (owner) ya da task'ı (editor) geri yükler
+func (h *TrashHandler) Restore(c *gin.Context) {
+	switch c.Param("type") {
+	case "board":
+		h.restoreBoard(c)
+	case "task":
+		h.restoreTask(c)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type"})
+	}
+}
+
+func (h *TrashHandler) restoreBoard(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB.Unscoped(), c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil || !board.DeletedAt.Valid {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found in trash"})
+		return
+	}
+	before := board
+
+	// Board ile aynı anda silinen task'lar da geri gelir; daha önce tek tek silinenler çöp kutusunda kalır
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockBoard(tx, board.ID); err != nil {
+			return err
+		}
+		var tasks []models.Task
+		if err := tx.Unscoped().Where("board_id = ? AND deleted_at = ?", board.ID, board.DeletedAt.Time).
+			Find(&tasks).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Task{}).
+			Where("board_id = ? AND deleted_at = ?", board.ID, board.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&board).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		// Kolonu olmayan eski task'lar (ör. boş status) board'un geri gelmesini engellemez
+		columns, err := boardColumns(tx, board.ID)
+		if err != nil {
+			return err
+		}
+		names := columnNames(columns)
+		var checked []models.Task
+		for _, task := range tasks {
+			if containsString(names, task.Status) {
+				checked = append(checked, task)
+			}
+		}
+		if err := validateRestored(tx, checked); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditRestore, EntityType: "board", EntityID: board.ID, BoardID: board.ID, Before: before, After: board,
+		})
+	})
+	if err != nil {
+		respondRestoreError(c, err)
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": board})
+}
+
+func (h *TrashHandler) restoreTask(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	var task models.Task
+	if err := h.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&task, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found in trash"})
+		return
+	}
+
+	// Board'u da silinmişse önce board geri yüklenmeli
+	board, err := findBoardForUser(h.DB.Unscoped(), task.BoardID, userID, orgID, models.BoardRoleEditor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found in trash"})
+		return
+	}
+	if board.DeletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Restore the board first"})
+		return
+	}
+
+	// Task ile aynı anda silinen alt task'lar da geri gelir
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockBoard(tx, task.BoardID); err != nil {
+			return err
+		}
+		ids, err := subtreeTaskIDs(tx, task.ID, task.DeletedAt)
+		if err != nil {
+			return err
+		}
+		var subtasks []models.Task
+		if len(ids) > 0 {
+			if err := tx.Unscoped().Where("id IN ?", ids).Order("id").Find(&subtasks).Error; err != nil {
+				return err
+			}
+		}
+
+		// Üst task hâlâ çöp kutusundaysa task üst seviyeye çıkar
+		detach := false
+		if task.ParentID != nil {
+			var count int64
+			if err := tx.Model(&models.Task{}).Where("id = ?", *task.ParentID).Count(&count).Error; err != nil {
+				return err
+			}
+			detach = count == 0
+		}
+
+		columns, err := boardColumns(tx, task.BoardID)
+		if err != nil {
+			return err
+		}
+		if err := restoreTaskRow(tx, c, &task, columns, detach); err != nil {
+			return err
+		}
+		for i := range subtasks {
+			if err := restoreTaskRow(tx, c, &subtasks[i], columns, false); err != nil {
+				return err
+			}
+		}
+		// Alt task'lar da geri geldikten sonra kontrol edilir, tamamlanmış üst task açık alt task'la dönmesin
+		return validateRestored(tx, append([]models.Task{task}, subtasks...))
+	})
+	if err != nil {
+		respondRestoreError(c, err)
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, task.BoardID)
+
+	c.JSON(http.StatusOK, gin.H{"data": task})
+}
+
+// validateRestored geri yüklenen task'ları kolonlarına yeni eklenmiş gibi workflow'a göre kontrol eder
+// (WIP limiti, açık alt task ve blocker). Board kilitliyken, tüm task'lar geri yüklendikten sonra çağrılmalı.
+func validateRestored(tx *gorm.DB, tasks []models.Task) error {
+	for _, task := range tasks {
+		if err := validateStatusChange(tx, task.BoardID, task.ID, "", task.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// respondRestoreError workflow'a uymayan geri yükleme 409 ile reddedilir, transaction geri alınır
+func respondRestoreError(c *gin.Context, err error) {
+	if werr, ok := err.(*workflowError); ok {
+		body := gin.H{"error": werr.Message}
+		for k, v := range werr.Details {
+			body[k] = v
+		}
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+}
+
+// restoreTaskRow task'ı çöp kutusundan çıkarır. Silindiği sürede kolona eklenen task'larla çakışmasın
+// diye kolonun sonuna yeni rank alır; kolonu kaldırılmışsa ilk kolona gider.
+func restoreTaskRow(tx *gorm.DB, c *gin.Context, task *models.Task, columns []models.BoardColumn, detach bool) error {
+	before := *task
+
+	updates := map[string]any{"deleted_at": nil}
+	status := task.Status
+	if len(columns) > 0 && !containsString(columnNames(columns), status) {
+		status = columns[0].Name
+		updates["status"] = status
+	}
+	if detach {
+		updates["parent_id"] = nil
+	}
+	var err error
+	if updates["rank"], err = rankAtEnd(tx, task.BoardID, status, task.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Model(task).Updates(updates).Error; err != nil {
+		return err
+	}
+	return recordAudit(tx, c, auditEntry{
+		Action: models.AuditRestore, EntityType: "task", EntityID: task.ID, BoardID: task.BoardID, TaskID: task.ID, Before: before, After: *task,
+	})
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PurgeExpired saklama süresi dolmuş board ve task'ları kalıcı olarak siler
+func (h *TrashHandler) PurgeExpired() error {
+	cutoff := time.Now().Add(-trashRetention())
+
+	var boards []models.Board
+	if err := h.DB.Unscoped().Where("deleted_at < ?", cutoff).Find(&boards).Error; err != nil {
+		return err
+	}
+	for _, board := range boards {
+		var files []string
+		err := h.DB.Transaction(func(tx *gorm.DB) (err error) {
+			if files, err = purgeBoard(tx, board.ID); err != nil {
+				return err
+			}
+			return writeAudit(tx, "", auditEntry{
+				Action: models.AuditPurge, EntityType: "board", EntityID: board.ID, BoardID: board.ID, OrgID: board.OrganizationID, Before: board,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		removeAttachmentFiles(h.Ctx, h.Storage, files)
+	}
+
+	var tasks []models.Task
+	if err := h.DB.Unscoped().Where("deleted_at < ?", cutoff).Find(&tasks).Error; err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		var files []string
+		err := h.DB.Transaction(func(tx *gorm.DB) (err error) {
+			var board models.Board
+			if err := tx.Unscoped().Select("id", "organization_id").First(&board, task.BoardID).Error; err != nil {
+				return err
+			}
+			if files, err = purgeTasks(tx, []uint{task.ID}); err != nil {
+				return err
+			}
+			return writeAudit(tx, "", auditEntry{
+				Action: models.AuditPurge, EntityType: "task", EntityID: task.ID, BoardID: task.BoardID, TaskID: task.ID, OrgID: board.OrganizationID, Before: task,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		removeAttachmentFiles(h.Ctx, h.Storage, files)
+	}
+
+	if len(boards) > 0 || len(tasks) > 0 {
+		log.Printf("🗑️ purged %d boards and %d tasks from trash", len(boards), len(tasks))
+	}
+	return nil
+}
+
+// RunPurge çöp kutusunu interval aralıklarla temizler, main'de goroutine olarak çalıştırılır
+func (h *TrashHandler) RunPurge(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if ok, err := h.RDB.SetNX(h.Ctx, trashPurgeLockKey, 1, interval/2).Result(); err == nil && ok {
+			if err := h.PurgeExpired(); err != nil {
+				log.Println("trash purge error:", err)
+			}
+		}
+		<-ticker.C
+	}
+}
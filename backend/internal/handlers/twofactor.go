@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Kurulumda üretilen secret kod doğrulanana kadar Redis'te bekler
+const totpSetupTTL = 10 * time.Minute
+
+func totpSetupKey(userID uint) string {
+	return fmt.Sprintf("totp_setup_%d", userID)
+}
+
+// verifySecondFactor TOTP kodunu ya da kullanılmamış bir kurtarma kodunu doğrular.
+// Kurtarma kodu kullanıldı olarak işaretlenir ve tekrar geçmez.
+func (h *UserHandler) verifySecondFactor(user models.User, code string) (bool, error) {
+	if user.TOTPEnabledAt == nil {
+		return false, nil
+	}
+	if step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return auth.UseTOTPStep(h.Ctx, h.RDB, user.ID, step)
+	}
+
+	result := h.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, auth.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// replaceRecoveryCodes kullanıcının kurtarma kodlarını yenileriyle değiştirir, düz metin kodları bir kez döner
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	rows := make([]models.RecoveryCode, len(codes))
+	for i, code := range codes {
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: auth.HashRecoveryCode(code)}
+	}
+	return codes, tx.Create(&rows).Error
+}
+
+// twoFactorRequiredBy kullanıcının üye olduğu ve 2FA zorunlu olan workspace'ler
+func twoFactorRequiredBy(db *gorm.DB, userID uint) []models.Organization {
+	var orgs []models.Organization
+	db.Where("require_two_factor AND id IN (?)",
+		db.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Find(&orgs)
+	return orgs
+}
+
+// twoFactorSatisfied workspace 2FA istiyorsa kullanıcının 2FA'sı açık mı
+func twoFactorSatisfied(db *gorm.DB, orgID, userID uint) bool {
+	var org models.Organization
+	if err := db.Select("id", "require_two_factor").First(&org, orgID).Error; err != nil {
+		return false
+	}
+	if !org.RequireTwoFactor {
+		return true
+	}
+
+	var count int64
+	db.Model(&models.User{}).Where("id = ? AND totp_enabled_at IS NOT NULL", userID).Count(&count)
+	return count > 0
+}
+
+// GET /me/2fa - 2FA durumu, kalan kurtarma kodu ve 2FA isteyen workspace'ler
+func (h *UserHandler) GetTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var remaining int64
+	h.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TOTPEnabledAt != nil,
+		"enabled_at":               user.TOTPEnabledAt,
+		"recovery_codes_remaining": remaining,
+		"required_by":              twoFactorRequiredBy(h.DB, user.ID),
+	})
+}
+
+// POST /me/2fa/setup - yeni secret üretir; authenticator'a eklendikten sonra /me/2fa/enable ile doğrulanır
+func (h *UserHandler) SetupTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := h.RDB.Set(h.Ctx, totpSetupKey(user.ID), secret, totpSetupTTL).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": auth.TOTPURI(secret, user.Email),
+		"expires_in":  int(totpSetupTTL.Seconds()),
+	})
+}
+
+// POST /me/2fa/enable - kurulumdaki secret'tan üretilen kodla 2FA'yı açar, kurtarma kodlarını döner
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := h.RDB.Get(h.Ctx, totpSetupKey(user.ID)).Result()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending setup, call POST /me/2fa/setup first"})
+		return
+	}
+	step, ok := auth.ValidateTOTP(secret, input.Code, time.Now())
+	if ok {
+		ok, err = auth.UseTOTPStep(h.Ctx, h.RDB, user.ID, step)
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	before := user
+	now := time.Now()
+	user.TOTPSecret = secret
+	user.TOTPEnabledAt = &now
+
+	var codes []string
+	err = h.DB.Transaction(func(tx *gorm.DB) (err error) {
+		if err := tx.Model(&user).Updates(map[string]any{"totp_secret": secret, "totp_enabled_at": now}).Error; err != nil {
+			return err
+		}
+		if codes, err = replaceRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	h.RDB.Del(h.Ctx, totpSetupKey(user.ID))
+	// Diğer cihazlardaki oturumlar 2FA ile yeniden giriş yapmalı
+	auth.RevokeOtherSessions(h.Ctx, h.RDB, user.ID, c.GetString("session_id"))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recovery_codes": codes})
+}
+
+// POST /me/2fa/disable - şifre ve geçerli bir kodla 2FA'yı kapatır
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+	var input struct {
+		Password string `json:"password"`
+		Code     string `json:"code"` // TOTP ya da kurtarma kodu
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !checkPassword(user, input.Password) {
+		respondWrongPassword(c, user)
+		return
+	}
+
+	// 2FA isteyen workspace'lere erişim kaybolmasın
+	if orgs := twoFactorRequiredBy(h.DB, user.ID); len(orgs) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Two-factor authentication is required by these organizations",
+			"organizations": orgs,
+		})
+		return
+	}
+
+	ok, err := h.verifySecondFactor(user, input.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	before := user
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]any{"totp_secret": "", "totp_enabled_at": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// POST /me/2fa/recovery-codes - geçerli bir kodla kurtarma kodlarını yeniler, eskiler geçersiz olur
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	ok, err := h.verifySecondFactor(user, input.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	err = h.DB.Transaction(func(tx *gorm.DB) (err error) {
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// POST /login/2fa - şifreden sonra dönen challenge token ve TOTP/kurtarma koduyla girişi tamamlar
+func (h *UserHandler) LoginTwoFactor(c *gin.Context) {
+	var input struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.ChallengeToken == "" || input.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	userID, err := auth.LoginChallengeUser(h.Ctx, h.RDB, input.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	// Deneme kod kontrolünden önce sayılır
+	if err := auth.ReserveChallengeAttempt(h.Ctx, h.RDB, input.ChallengeToken); err != nil {
+		if err == auth.ErrTooManyAttempts {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Too many invalid codes, log in again"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	// Yanlış kodlar şifre denemeleriyle aynı sayaca yazılır
+	if h.loginBlocked(c, user.Email) {
+		return
+	}
+
+	ok, err := h.verifySecondFactor(user, input.Code)
+	if err != nil {
+		h.loginAttemptPassed(c, user.Email)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	if !ok {
+		h.loginFailed(c, user.Email, &user)
+		if err := auth.FailLoginChallenge(h.Ctx, h.RDB, input.ChallengeToken); err == auth.ErrTooManyAttempts {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Too many invalid codes, log in again"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+	h.loginAttemptPassed(c, user.Email)
+
+	// Aynı challenge ile ikinci oturum açılamaz
+	if consumed, err := auth.ConsumeLoginChallenge(h.Ctx, h.RDB, input.ChallengeToken); err != nil || !consumed {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge"})
+		return
+	}
+
+	h.loginSucceeded(user.Email)
+	h.issueTokens(c, &user)
+}
@@ -3,38 +3,53 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/mail"
 	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/oidc"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-var jwtSecret = []byte("supersecretkey") // production için env variable yap
-
 type UserHandler struct {
-	DB  *gorm.DB
-	RDB *redis.Client
-	Ctx context.Context
+	DB      *gorm.DB
+	RDB     *redis.Client
+	Storage storage.Storage // hesap silinince board eklerinin dosyaları
+	Mailer  mail.Mailer     // şifre sıfırlama ve e-posta doğrulama linkleri
+	OIDC    *oidc.Provider  // SSO; OIDC_ISSUER ayarlı değilse nil
+	Ctx     context.Context
 }
 
-func NewUserHandler(db *gorm.DB, rdb *redis.Client) *UserHandler {
+func NewUserHandler(db *gorm.DB, rdb *redis.Client, store storage.Storage, mailer mail.Mailer, provider *oidc.Provider) *UserHandler {
 	return &UserHandler{
-		DB:  db,
-		RDB: rdb,
-		Ctx: context.Background(),
+		DB:      db,
+		RDB:     rdb,
+		Storage: store,
+		Mailer:  mailer,
+		OIDC:    provider,
+		Ctx:     context.Background(),
 	}
 }
 
 // ------------------- READ -------------------
 // GET /users
 func (h *UserHandler) GetUsers(c *gin.Context) {
+	orgID := c.GetUint("org_id")
+	cacheKey := usersCacheKey(orgID)
+
 	// Önce cache kontrolü
-	cached, err := h.RDB.Get(h.Ctx, "users").Result()
+	cached, err := h.RDB.Get(h.Ctx, cacheKey).Result()
 	if err == nil && cached != "" {
 		var users []models.User
 		if err := json.Unmarshal([]byte(cached), &users); err == nil {
@@ -43,32 +58,61 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		}
 	}
 
-	// Cache yoksa DB'den çek
+	// Cache yoksa DB'den çek - sadece aktif workspace'in üyeleri
 	var users []models.User
-	if err := h.DB.Preload("Boards").Find(&users).Error; err != nil {
+	if err := h.DB.Where("id IN (?)", orgMemberIDs(h.DB, orgID)).
+		Preload("Boards", "organization_id = ?", orgID).
+		Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
 	// DB'den çekilen veriyi cache'e kaydet (1 saat)
 	data, _ := json.Marshal(users)
-	h.RDB.Set(h.Ctx, "users", data, time.Hour)
+	h.RDB.Set(h.Ctx, cacheKey, data, time.Hour)
 
 	c.JSON(http.StatusOK, gin.H{"data": users, "source": "db"})
 }
 
+// verifiedUserRole ADMIN_EMAIL adresi doğrulanınca kullanıcı admin olur; adresin sahibi olduğu
+// kanıtlanmadan (ör. sadece kayıt olarak) admin yetkisi verilmez
+func verifiedUserRole(user models.User) string {
+	adminEmail := os.Getenv("ADMIN_EMAIL")
+	if user.EmailVerifiedAt != nil && adminEmail != "" && strings.EqualFold(adminEmail, user.Email) {
+		return models.RoleAdmin
+	}
+	return user.Role
+}
+
 // POST /users
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var input struct {
 		Name     string `json:"name"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Language string `json:"language"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
+	input.Email = strings.TrimSpace(input.Email)
+	if !validEmail(input.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+		return
+	}
+	if len(input.Password) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+		return
+	}
+	if input.Language == "" {
+		input.Language = mail.DefaultLanguage
+	}
+	if !slices.Contains(mail.Languages, input.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language"})
+		return
+	}
 
 	// Şifreyi hashle
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
@@ -81,25 +125,39 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: string(hashedPassword),
+		Role:     models.RoleMember, // ADMIN_EMAIL ise e-posta doğrulanınca admin olur
+		Language: input.Language,
 	}
 
-	if err := h.DB.Create(&user).Error; err != nil {
+	// Her kullanıcı kendi kişisel workspace'i ile başlar
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := createPersonalOrganization(tx, &user); err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			ActorID: user.ID, Action: models.AuditCreate, EntityType: "user", EntityID: user.ID, After: user,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
-	// Cache temizle
-	h.RDB.Del(h.Ctx, "users")
+	// Hesap açılır ama e-posta doğrulanana kadar erişim kısıtlıdır
+	if err := h.sendVerificationEmail(user); err != nil {
+		log.Println("verification email error:", err)
+	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": user})
 }
 
 // PUT /users/:id
 func (h *UserHandler) UpdateUser(c *gin.Context) {
-	id := c.Param("id")
-	var user models.User
-
-	if err := h.DB.First(&user, id).Error; err != nil {
+	user, err := h.findUserInTenant(c, c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -108,6 +166,8 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		Name     string `json:"name"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Role     string `json:"role"`
+		Language string `json:"language"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -115,41 +175,115 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user.Name = input.Name
-	user.Email = input.Email
-	user.Password = input.Password
+	before := user
+
+	// Rolü sadece admin değiştirebilir
+	roleChanged := input.Role != "" && input.Role != user.Role
+	if roleChanged {
+		if c.GetString("role") != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change roles"})
+			return
+		}
+		if !models.ValidRole(input.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+			return
+		}
+		user.Role = input.Role
+	}
+
+	if input.Language != "" {
+		if !slices.Contains(mail.Languages, input.Language) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language"})
+			return
+		}
+		user.Language = input.Language
+	}
 
-	if err := h.DB.Save(&user).Error; err != nil {
+	// Boş gelen alanlar değişmez
+	if name := strings.TrimSpace(input.Name); name != "" {
+		user.Name = name
+	}
+
+	email := strings.TrimSpace(input.Email)
+	emailChanged := email != "" && email != user.Email
+	if emailChanged {
+		if !validEmail(email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+			return
+		}
+		if emailInUse(h.DB, email, user.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+		user.Email = email
+		// Yeni adres doğrulanana kadar erişim kısıtlanır
+		user.EmailVerifiedAt = nil
+	}
+
+	// Kendi şifresini değiştiren mevcut şifreyi doğrulamalı, bu yüzden /me/password kullanılır;
+	// burada sadece admin başka bir kullanıcının şifresini sıfırlayabilir
+	var sensitive []string
+	if input.Password != "" {
+		if user.ID == c.GetUint("user_id") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Use POST /me/password to change your own password"})
+			return
+		}
+		if len(input.Password) < minPasswordLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Password must be at least %d characters", minPasswordLength)})
+			return
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		user.Password = string(hashedPassword)
+		sensitive = append(sensitive, "Password")
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "user", EntityID: user.ID, Before: before, After: user, Sensitive: sensitive,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
 		return
 	}
 
+	// Şifre, rol ya da e-posta değiştiyse açık oturumları hemen kapat
+	if input.Password != "" || roleChanged || emailChanged {
+		auth.RevokeUserSessions(h.Ctx, h.RDB, user.ID)
+	}
+	if emailChanged {
+		h.afterEmailChange(user)
+	}
+
 	// Cache temizle
-	h.RDB.Del(h.Ctx, "users")
+	invalidateUsersCaches(h.Ctx, h.DB, h.RDB, user.ID)
 
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
 // DELETE /users/:id
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	id := c.Param("id")
-	var user models.User
-
-	if err := h.DB.First(&user, id).Error; err != nil {
+	user, err := h.findUserInTenant(c, c.Param("id"))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	if err := h.DB.Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+	// Kendi hesabını silmek onay ister
+	if user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use DELETE /me to delete your own account"})
 		return
 	}
 
-	// Cache temizle
-	h.RDB.Del(h.Ctx, "users")
-	h.RDB.Del(h.Ctx, "boards") // user silindi → boards da etkilenebilir
-
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+	// Kullanıcının board'ları ve diğer verileri DELETE /me'deki gibi temizlenir
+	h.removeAccount(c, user)
 }
 
 // ------------------- LOGIN -------------------
@@ -164,29 +298,164 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Kilitli hesap/IP şifre kontrol edilmeden reddedilir, deneme kontrolden önce sayılır
+	if h.loginBlocked(c, input.Email) {
+		return
+	}
+
 	var user models.User
 	if err := h.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		h.loginFailed(c, input.Email, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
 	// password kontrol
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		h.loginFailed(c, input.Email, &user)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	h.loginAttemptPassed(c, input.Email)
+
+	h.completeLogin(c, &user)
+}
+
+// completeLogin kimliği doğrulanmış kullanıcıya token verir.
+// 2FA açıksa token yerine kısa ömürlü challenge döner, giriş POST /login/2fa ile tamamlanır.
+// Sayaç challenge'da sıfırlanmaz, yoksa şifreyi bilen biri her challenge'da yeni kod denemesi kazanırdı.
+func (h *UserHandler) completeLogin(c *gin.Context, user *models.User) {
+	if user.TOTPEnabledAt != nil {
+		challenge, err := auth.NewLoginChallenge(h.Ctx, h.RDB, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create challenge"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+			"expires_in":          int(auth.LoginChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	h.loginSucceeded(user.Email)
+	h.issueTokens(c, user)
+}
+
+// issueTokens varsayılan workspace ile yeni oturum açar, access + refresh token döner
+func (h *UserHandler) issueTokens(c *gin.Context, user *models.User) {
+	session, refreshToken, err := auth.NewSession(h.Ctx, h.RDB, user.ID, defaultOrganizationID(h.DB, user.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	respondWithTokens(c, user, session, refreshToken)
+}
 
-	// JWT token üret
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // 24 saat geçerli
+// accessTokenFor kullanıcı ve oturum bilgisinden access token üretir
+func accessTokenFor(user *models.User, session *auth.Session) (string, error) {
+	return auth.GenerateAccessToken(auth.Claims{
+		UserID:          user.ID,
+		Role:            user.Role,
+		OrganizationID:  session.OrganizationID,
+		SessionID:       session.ID,
+		EmailUnverified: user.EmailVerifiedAt == nil,
 	})
+}
 
-	tokenString, err := token.SignedString(jwtSecret)
+func respondWithTokens(c *gin.Context, user *models.User, session *auth.Session, refreshToken string) {
+	tokenString, err := accessTokenFor(user, session)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"expires_in":    int(auth.AccessTokenTTL.Seconds()),
+	})
+}
+
+// POST /token/refresh
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || input.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	session, refreshToken, err := auth.RotateRefreshToken(h.Ctx, h.RDB, input.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+
+	// Rol değişmiş olabilir, claim'leri DB'deki güncel kullanıcıdan üret
+	var user models.User
+	if err := h.DB.First(&user, session.UserID).Error; err != nil {
+		auth.RevokeSession(h.Ctx, h.RDB, session.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked refresh token"})
+		return
+	}
+
+	// Workspace'ten çıkarıldıysa ya da workspace 2FA istiyorsa varsayılan workspace'e dön
+	if _, err := orgRole(h.DB, session.OrganizationID, user.ID); err != nil || !twoFactorSatisfied(h.DB, session.OrganizationID, user.ID) {
+		session.OrganizationID = defaultOrganizationID(h.DB, user.ID)
+		auth.SetSessionOrganization(h.Ctx, h.RDB, session.ID, session.OrganizationID)
+	}
+
+	respondWithTokens(c, &user, session, refreshToken)
+}
+
+// POST /logout
+func (h *UserHandler) Logout(c *gin.Context) {
+	sessionID := c.GetString("session_id")
+
+	if err := auth.RevokeSession(h.Ctx, h.RDB, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// POST /users/:id/sessions/revoke - kullanıcının tüm oturumlarını ve kişisel API token'larını kapatır (kill-switch)
+func (h *UserHandler) RevokeUserSessions(c *gin.Context) {
+	user, err := h.findUserInTenant(c, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := auth.RevokeUserSessions(h.Ctx, h.RDB, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	if err := h.DB.Where("user_id = ?", user.ID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked"})
+}
+
+// findUserInTenant kullanıcının kendisini ya da aktif workspace'teki bir kullanıcıyı getirir
+func (h *UserHandler) findUserInTenant(c *gin.Context, id string) (models.User, error) {
+	var user models.User
+	if err := h.DB.First(&user, id).Error; err != nil {
+		return user, err
+	}
+
+	if user.ID != c.GetUint("user_id") {
+		if _, err := orgRole(h.DB, c.GetUint("org_id"), user.ID); err != nil {
+			return user, err
+		}
+	}
+
+	return user, nil
 }
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/ahmetcanc/TaskMan/internal/realtime"
+	"github.com/ahmetcanc/TaskMan/internal/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+	Ctx context.Context
+}
+
+func NewWebhookHandler(db *gorm.DB, rdb *redis.Client) *WebhookHandler {
+	return &WebhookHandler{
+		DB:  db,
+		RDB: rdb,
+		Ctx: context.Background(),
+	}
+}
+
+// enqueueWebhooks event'e abone aktif webhook'lar için bekleyen teslimat oluşturur
+func enqueueWebhooks(tx *gorm.DB, orgID uint, e realtime.Event, payload []byte) error {
+	var hooks []models.Webhook
+	if err := tx.Where("organization_id = ? AND active AND (board_id IS NULL OR board_id = ?)", orgID, e.BoardID).
+		Find(&hooks).Error; err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if !webhook.Subscribed(hook.Events, e.Type) {
+			continue
+		}
+		if err := tx.Create(&models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventType:     e.Type,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// respondInvalidWebhookURL adres geçersizse ya da iç ağı gösteriyorsa 400 döner
+func respondInvalidWebhookURL(c *gin.Context, raw string) bool {
+	err := webhook.ValidURL(raw)
+	if errors.Is(err, webhook.ErrBlockedAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook url must be a public address"})
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid url"})
+		return true
+	}
+	return false
+}
+
+// webhookEvents event listesini doğrular ve virgülle birleştirir
+func webhookEvents(events []string) (string, bool) {
+	var unique []string
+	for _, e := range events {
+		if !webhook.ValidEvent(e) {
+			return "", false
+		}
+		if !containsString(unique, e) {
+			unique = append(unique, e)
+		}
+	}
+	joined := strings.Join(unique, ",")
+	return joined, len(unique) > 0 && len(joined) <= 1000
+}
+
+// canManageWebhook workspace webhook'larını workspace admin'i, board webhook'larını board owner'ı yönetir
+func canManageWebhook(db *gorm.DB, boardID *uint, userID, orgID uint) bool {
+	if boardID == nil {
+		_, err := findOrganizationForUser(db, orgID, userID, models.OrgRoleAdmin)
+		return err == nil
+	}
+	_, err := findBoardForUser(db, *boardID, userID, orgID, models.BoardRoleOwner)
+	return err == nil
+}
+
+// findWebhookForUser aktif workspace'teki webhook'u, kullanıcı yönetebiliyorsa getirir
+func findWebhookForUser(db *gorm.DB, webhookID any, userID, orgID uint) (models.Webhook, error) {
+	var hook models.Webhook
+	if err := db.Where("organization_id = ?", orgID).First(&hook, webhookID).Error; err != nil {
+		return hook, err
+	}
+	if !canManageWebhook(db, hook.BoardID, userID, orgID) {
+		return hook, gorm.ErrRecordNotFound
+	}
+	return hook, nil
+}
+
+func webhookAudit(hook models.Webhook, action string, before, after any) auditEntry {
+	e := auditEntry{Action: action, EntityType: "webhook", EntityID: hook.ID, Before: before, After: after}
+	if hook.BoardID != nil {
+		e.BoardID = *hook.BoardID
+	}
+	return e
+}
+
+// GET /webhooks - aktif workspace'te yönetilebilen webhook'lar, ?board_id= ile filtrelenebilir
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	query := h.DB.Where("organization_id = ?", orgID)
+	if _, err := findOrganizationForUser(h.DB, orgID, userID, models.OrgRoleAdmin); err != nil {
+		// Workspace admin'i değilse sadece owner olduğu board'ların webhook'ları
+		query = query.Where("board_id IN (?)", memberBoardIDs(h.DB, userID, orgID).Where("board_members.role = ?", models.BoardRoleOwner))
+	}
+	if boardID := c.Query("board_id"); boardID != "" {
+		query = query.Where("board_id = ?", boardID)
+	}
+
+	var hooks []models.Webhook
+	if err := query.Order("id").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": hooks})
+}
+
+// POST /webhooks - yeni webhook; imza anahtarı sadece bu response'ta döner
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	var input struct {
+		URL     string   `json:"url"`
+		Events  []string `json:"events"`
+		BoardID *uint    `json:"board_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if respondInvalidWebhookURL(c, input.URL) {
+		return
+	}
+	events, ok := webhookEvents(input.Events)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid events"})
+		return
+	}
+	if !canManageWebhook(h.DB, input.BoardID, userID, orgID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	hook := models.Webhook{
+		OrganizationID: orgID,
+		BoardID:        input.BoardID,
+		UserID:         userID,
+		URL:            input.URL,
+		Secret:         secret,
+		Events:         events,
+		Active:         true,
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&hook).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, webhookAudit(hook, models.AuditCreate, nil, hook))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": hook, "secret": secret})
+}
+
+// PUT /webhooks/:id - url, event listesi ya da aktiflik; rotate_secret ile yeni imza anahtarı
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	hook, err := findWebhookForUser(h.DB, c.Param("id"), userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or access denied"})
+		return
+	}
+
+	var input struct {
+		URL          string   `json:"url"`
+		Events       []string `json:"events"`
+		Active       *bool    `json:"active"`
+		RotateSecret bool     `json:"rotate_secret"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := hook
+	if input.URL != "" {
+		if respondInvalidWebhookURL(c, input.URL) {
+			return
+		}
+		hook.URL = input.URL
+	}
+	if input.Events != nil {
+		events, ok := webhookEvents(input.Events)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid events"})
+			return
+		}
+		hook.Events = events
+	}
+	if input.Active != nil {
+		hook.Active = *input.Active
+	}
+
+	response := gin.H{}
+	entry := webhookAudit(hook, models.AuditUpdate, before, hook)
+	if input.RotateSecret {
+		if hook.Secret, err = newWebhookSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+		response["secret"] = hook.Secret
+		entry.Sensitive = []string{"Secret"}
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&hook).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, entry)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	response["data"] = hook
+	c.JSON(http.StatusOK, response)
+}
+
+// DELETE /webhooks/:id - webhook'u teslimat log'uyla birlikte siler
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	hook, err := findWebhookForUser(h.DB, c.Param("id"), userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or access denied"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&hook).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, webhookAudit(hook, models.AuditDelete, hook, nil))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GET /webhooks/:id/deliveries - teslimat log'u, ?status= ile filtrelenebilir, yeniden eskiye
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	hook, err := findWebhookForUser(h.DB, c.Param("id"), userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or access denied"})
+		return
+	}
+
+	// Liste hafif kalsın, payload ve response detayda döner
+	query := h.DB.Omit("payload", "response_body").Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := auditPage(query, c).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+func findDelivery(db *gorm.DB, webhookID uint, deliveryID string) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := db.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error
+	return delivery, err
+}
+
+// GET /webhooks/:id/deliveries/:deliveryId - payload ve son response ile teslimat detayı
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	hook, err := findWebhookForUser(h.DB, c.Param("id"), userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or access denied"})
+		return
+	}
+
+	delivery, err := findDelivery(h.DB, hook.ID, c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": delivery})
+}
+
+// POST /webhooks/:id/deliveries/:deliveryId/redeliver - aynı payload'ı yeni bir teslimat olarak sıraya koyar
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	hook, err := findWebhookForUser(h.DB, c.Param("id"), userID, orgID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found or access denied"})
+		return
+	}
+
+	original, err := findDelivery(h.DB, hook.ID, c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:     hook.ID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := h.DB.Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": delivery})
+}
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// workflowError board workflow'una uymayan status değişikliği (422)
+type workflowError struct {
+	Message string
+	Details gin.H
+}
+
+func (e *workflowError) Error() string {
+	return e.Message
+}
+
+// respondTaskWriteError alan hatalarını 400, workflow hatalarını 422, diğerlerini 500 olarak döner
+func respondTaskWriteError(c *gin.Context, err error) {
+	if ferr, ok := err.(*fieldError); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ferr.Message})
+		return
+	}
+	if werr, ok := err.(*workflowError); ok {
+		body := gin.H{"error": werr.Message}
+		for k, v := range werr.Details {
+			body[k] = v
+		}
+		c.JSON(http.StatusUnprocessableEntity, body)
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+}
+
+// createDefaultColumns yeni board için varsayılan kolonları oluşturur
+func createDefaultColumns(tx *gorm.DB, boardID uint) error {
+	for i, name := range models.DefaultColumns {
+		if err := tx.Create(&models.BoardColumn{BoardID: boardID, Name: name, Position: i, Done: name == models.DefaultDoneColumn}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func boardColumns(db *gorm.DB, boardID uint) ([]models.BoardColumn, error) {
+	var columns []models.BoardColumn
+	err := db.Where("board_id = ?", boardID).Order("position, id").Find(&columns).Error
+	return columns, err
+}
+
+func columnNames(columns []models.BoardColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// defaultStatus board'un ilk kolonu; status'u boş task'lar buraya düşer
+func defaultStatus(db *gorm.DB, boardID uint) string {
+	columns, err := boardColumns(db, boardID)
+	if err != nil || len(columns) == 0 {
+		return ""
+	}
+	return columns[0].Name
+}
+
+// lockBoard board satırını transaction sonuna kadar kilitler, WIP kontrollerini sıraya sokar.
+// NO KEY UPDATE: task insert'lerinin FK kilidiyle (KEY SHARE) çakışmaz. Çöp kutusundaki board'lar da kilitlenebilir.
+func lockBoard(tx *gorm.DB, boardID uint) error {
+	return tx.Unscoped().Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id").First(&models.Board{}, boardID).Error
+}
+
+// validateStatusChange task'ın board'da from → to geçişini workflow'a göre doğrular.
+// Yeni task ya da board değişikliğinde from boş gelir ve sadece kolon + WIP limiti kontrol edilir.
+// WIP limiti board satırını kilitlediği için transaction içinde çağrılmalı.
+func validateStatusChange(db *gorm.DB, boardID, taskID uint, from, to string) error {
+	columns, err := boardColumns(db, boardID)
+	if err != nil {
+		return err
+	}
+
+	var fromCol, toCol *models.BoardColumn
+	for i := range columns {
+		if columns[i].Name == from {
+			fromCol = &columns[i]
+		}
+		if columns[i].Name == to {
+			toCol = &columns[i]
+		}
+	}
+
+	if toCol == nil {
+		return &workflowError{
+			Message: "Unknown status for this board",
+			Details: gin.H{"status": to, "allowed": columnNames(columns)},
+		}
+	}
+
+	if fromCol != nil && fromCol.ID != toCol.ID {
+		var transitions []models.ColumnTransition
+		if err := db.Where("board_id = ?", boardID).Find(&transitions).Error; err != nil {
+			return err
+		}
+
+		// Geçiş tanımlanmamışsa workflow serbest
+		if len(transitions) > 0 {
+			allowed := []string{}
+			permitted := false
+			for _, t := range transitions {
+				if t.FromColumnID != fromCol.ID {
+					continue
+				}
+				if t.ToColumnID == toCol.ID {
+					permitted = true
+				}
+				for _, col := range columns {
+					if col.ID == t.ToColumnID {
+						allowed = append(allowed, col.Name)
+					}
+				}
+			}
+
+			if !permitted {
+				return &workflowError{
+					Message: "Illegal status transition",
+					Details: gin.H{"from": from, "to": to, "allowed": allowed},
+				}
+			}
+		}
+	}
+
+	// Açık alt task'ı olan task tamamlanmış kolona taşınamaz
+	if toCol.Done && taskID != 0 && (fromCol == nil || !fromCol.Done) {
+		open, err := openSubtaskIDs(db, taskID)
+		if err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			return &workflowError{
+				Message: "Task has open subtasks",
+				Details: gin.H{"status": to, "open_subtasks": open},
+			}
+		}
+	}
+
+	// Board ayarı açıksa açık blocker'ı olan task tamamlanmış kolona taşınamaz
+	if toCol.Done && taskID != 0 && (fromCol == nil || !fromCol.Done) {
+		var board models.Board
+		if err := db.Select("id", "enforce_dependencies").First(&board, boardID).Error; err != nil {
+			return err
+		}
+		if board.EnforceDependencies {
+			open, err := openBlockerIDs(db, taskID)
+			if err != nil {
+				return err
+			}
+			if len(open) > 0 {
+				return &workflowError{
+					Message: "Task is blocked by open tasks",
+					Details: gin.H{"status": to, "blocked_by": open},
+				}
+			}
+		}
+	}
+
+	if toCol.WIPLimit > 0 && (fromCol == nil || fromCol.ID != toCol.ID) {
+		// Aynı board'a eşzamanlı eklemeler sayımı birlikte geçmesin
+		if err := lockBoard(db, boardID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := db.Model(&models.Task{}).
+			Where("board_id = ? AND status = ? AND id <> ?", boardID, toCol.Name, taskID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count >= int64(toCol.WIPLimit) {
+			return &workflowError{
+				Message: "WIP limit reached for column",
+				Details: gin.H{"status": to, "wip_limit": toCol.WIPLimit},
+			}
+		}
+	}
+
+	return nil
+}
+
+// GET /boards/:id/columns - kolonlar ve izin verilen geçişler
+func (h *BoardHandler) GetColumns(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleViewer)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	columns, err := boardColumns(h.DB, board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	var transitions []models.ColumnTransition
+	if err := h.DB.Where("board_id = ?", board.ID).Find(&transitions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": columns, "transitions": transitions})
+}
+
+// POST /boards/:id/columns - kolon ekle (sadece owner)
+func (h *BoardHandler) CreateColumn(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Name     string `json:"name"`
+		Position *int   `json:"position"`
+		WIPLimit int    `json:"wip_limit"`
+		Done     bool   `json:"done"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == "" || input.WIPLimit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	column := models.BoardColumn{
+		BoardID:  board.ID,
+		Name:     input.Name,
+		WIPLimit: input.WIPLimit,
+		Done:     input.Done,
+	}
+
+	// Pozisyon verilmezse sona ekle
+	if input.Position != nil {
+		column.Position = *input.Position
+	} else {
+		var maxPosition *int
+		h.DB.Model(&models.BoardColumn{}).Where("board_id = ?", board.ID).Select("MAX(position)").Scan(&maxPosition)
+		if maxPosition != nil {
+			column.Position = *maxPosition + 1
+		}
+	}
+
+	var existing int64
+	h.DB.Model(&models.BoardColumn{}).Where("board_id = ? AND name = ?", board.ID, input.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Column already exists"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&column).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditCreate, EntityType: "column", EntityID: column.ID, BoardID: board.ID, After: column,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusCreated, gin.H{"data": column})
+}
+
+// PUT /boards/:id/columns/:columnId - kolonu yeniden adlandır, taşı, WIP limitini ya da done işaretini değiştir (sadece owner)
+func (h *BoardHandler) UpdateColumn(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var column models.BoardColumn
+	if err := h.DB.Where("board_id = ?", board.ID).First(&column, c.Param("columnId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Column not found"})
+		return
+	}
+
+	var input struct {
+		Name     string `json:"name"`
+		Position *int   `json:"position"`
+		WIPLimit *int   `json:"wip_limit"`
+		Done     *bool  `json:"done"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || (input.WIPLimit != nil && *input.WIPLimit < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	before := column
+	oldName := column.Name
+	if input.Name != "" && input.Name != oldName {
+		var existing int64
+		h.DB.Model(&models.BoardColumn{}).Where("board_id = ? AND name = ?", board.ID, input.Name).Count(&existing)
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Column already exists"})
+			return
+		}
+		column.Name = input.Name
+	}
+	if input.Position != nil {
+		column.Position = *input.Position
+	}
+	if input.WIPLimit != nil {
+		column.WIPLimit = *input.WIPLimit
+	}
+	if input.Done != nil {
+		column.Done = *input.Done
+	}
+
+	// Kolon adı değiştiyse task status'leri de aynı transaction'da güncellenir
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&column).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "column", EntityID: column.ID, BoardID: board.ID, Before: before, After: column,
+		}); err != nil {
+			return err
+		}
+		if column.Name == oldName {
+			return nil
+		}
+		return tx.Model(&models.Task{}).
+			Where("board_id = ? AND status = ?", board.ID, oldName).
+			Update("status", column.Name).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"data": column})
+}
+
+// DELETE /boards/:id/columns/:columnId - boş kolonu sil (sadece owner)
+func (h *BoardHandler) DeleteColumn(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var column models.BoardColumn
+	if err := h.DB.Where("board_id = ?", board.ID).First(&column, c.Param("columnId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Column not found"})
+		return
+	}
+
+	var taskCount int64
+	h.DB.Model(&models.Task{}).Where("board_id = ? AND status = ?", board.ID, column.Name).Count(&taskCount)
+	if taskCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Column still has tasks"})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("from_column_id = ? OR to_column_id = ?", column.ID, column.ID).
+			Delete(&models.ColumnTransition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&column).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditDelete, EntityType: "column", EntityID: column.ID, BoardID: board.ID, Before: column,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	invalidateBoardCaches(h.Ctx, h.DB, h.RDB, board.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Column deleted"})
+}
+
+// PUT /boards/:id/transitions - izin verilen geçişlerin tamamını değiştir (sadece owner).
+// Boş liste workflow'u serbest bırakır.
+func (h *BoardHandler) SetTransitions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	orgID := c.GetUint("org_id")
+
+	board, err := findBoardForUser(h.DB, c.Param("id"), userID, orgID, models.BoardRoleOwner)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found or access denied"})
+		return
+	}
+
+	var input struct {
+		Transitions []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"transitions"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	columns, err := boardColumns(h.DB, board.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+	columnIDs := make(map[string]uint, len(columns))
+	columnByID := make(map[uint]string, len(columns))
+	for _, col := range columns {
+		columnIDs[col.Name] = col.ID
+		columnByID[col.ID] = col.Name
+	}
+
+	transitions := make([]models.ColumnTransition, 0, len(input.Transitions))
+	for _, t := range input.Transitions {
+		fromID, okFrom := columnIDs[t.From]
+		toID, okTo := columnIDs[t.To]
+		if !okFrom || !okTo || fromID == toID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transition", "from": t.From, "to": t.To})
+			return
+		}
+		transitions = append(transitions, models.ColumnTransition{BoardID: board.ID, FromColumnID: fromID, ToColumnID: toID})
+	}
+
+	// Audit'te geçişler "from → to" listesi olarak tutulur
+	describe := func(list []models.ColumnTransition) string {
+		parts := make([]string, 0, len(list))
+		for _, t := range list {
+			parts = append(parts, columnByID[t.FromColumnID]+" → "+columnByID[t.ToColumnID])
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var previous []models.ColumnTransition
+		if err := tx.Where("board_id = ?", board.ID).Order("id").Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("board_id = ?", board.ID).Delete(&models.ColumnTransition{}).Error; err != nil {
+			return err
+		}
+		if len(transitions) > 0 {
+			if err := tx.Create(&transitions).Error; err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, c, auditEntry{
+			Action: models.AuditUpdate, EntityType: "board", EntityID: board.ID, BoardID: board.ID,
+			Before: gin.H{"Transitions": describe(previous)}, After: gin.H{"Transitions": describe(transitions)},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": transitions})
+}
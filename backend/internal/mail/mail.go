@@ -0,0 +1,132 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// Message gönderilecek e-posta, düz metin ve HTML alternatifleriyle
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer e-posta gönderimi, SMTP ya da (SMTP ayarlı değilse) sadece log
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Connect SMTP_HOST ayarlıysa SMTP, değilse log'a yazan mailer döner.
+// Lokal test için MailHog: SMTP_HOST=mailhog SMTP_PORT=1025
+func Connect() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("📭 SMTP_HOST not set, emails will only be logged")
+		return LogMailer{}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "TaskMan <no-reply@taskman.local>"
+	}
+
+	log.Printf("📬 SMTP relay: %s:%s", host, port)
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// SMTPMailer SMTP relay üzerinden gönderir; kullanıcı adı yoksa kimlik doğrulama yapılmaz
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body, err := Build(m.From, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.Host+":"+m.Port, auth, envelopeAddress(m.From), []string{msg.To}, body)
+}
+
+// envelopeAddress "Ad <adres>" formatından adresi alır
+func envelopeAddress(from string) string {
+	if start, end := strings.Index(from, "<"), strings.Index(from, ">"); start >= 0 && end > start {
+		return from[start+1 : end]
+	}
+	return from
+}
+
+// LogMailer e-postaları göndermeden log'a yazar
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("✉️ email to %s: %s", msg.To, msg.Subject)
+	return nil
+}
+
+// Build multipart/alternative (text + html) MIME mesajı oluşturur
+func Build(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
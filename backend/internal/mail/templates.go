@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Desteklenen e-posta dilleri, bilinmeyen dil için DefaultLanguage kullanılır
+var Languages = []string{"en", "tr"}
+
+const DefaultLanguage = "en"
+
+// Render templates/<lang>/<name>.txt ve .html şablonlarından e-posta oluşturur.
+// .txt dosyasındaki "subject" bloğu konu satırıdır; "item" bloğu item.txt'den gelir.
+func Render(lang, name, to string, data any) (Message, error) {
+	if !slices.Contains(Languages, lang) {
+		lang = DefaultLanguage
+	}
+	item := "templates/" + lang + "/item.txt"
+
+	text, err := texttemplate.ParseFS(templateFS, item, "templates/"+lang+"/"+name+".txt")
+	if err != nil {
+		return Message{}, err
+	}
+	html, err := htmltemplate.ParseFS(templateFS, item, "templates/"+lang+"/"+name+".html")
+	if err != nil {
+		return Message{}, err
+	}
+
+	var subject, body, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.ExecuteTemplate(&body, name+".txt", data); err != nil {
+		return Message{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, name+".html", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,270 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Bu kadar başarısız denemeden sonra e-posta failed olur
+	maxAttempts = 5
+	batchSize   = 50
+	// Şablonda gösterilen yorum uzunluğu
+	maxCommentLength = 500
+	// Gönderim için alınan e-posta bu süre içinde sonuçlanmazsa (ör. instance çöktü) tekrar denenir
+	claimLease = 5 * time.Minute
+)
+
+// Item e-postadaki tek bildirim
+type Item struct {
+	Type        string
+	ActorName   string
+	TaskTitle   string
+	BoardTitle  string
+	TaskURL     string
+	CommentBody string
+	DueAt       string
+}
+
+// Worker bekleyen bildirim e-postalarını gönderir; EmailDigest açık kullanıcılara günde bir özet gider
+type Worker struct {
+	DB         *gorm.DB
+	Mailer     Mailer
+	AppURL     string // task linkleri için frontend adresi
+	DigestHour int    // özetin gönderildiği saat (UTC)
+}
+
+// AppURL e-postalardaki linkler için frontend adresi, APP_URL (varsayılan http://localhost:5173)
+func AppURL() string {
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	return strings.TrimRight(appURL, "/")
+}
+
+// NewWorker APP_URL ve EMAIL_DIGEST_HOUR (varsayılan 8) ile ayarlanır
+func NewWorker(db *gorm.DB, mailer Mailer) *Worker {
+	hour, err := strconv.Atoi(os.Getenv("EMAIL_DIGEST_HOUR"))
+	if err != nil || hour < 0 || hour > 23 {
+		hour = 8
+	}
+
+	return &Worker{
+		DB:         db,
+		Mailer:     mailer,
+		AppURL:     AppURL(),
+		DigestHour: hour,
+	}
+}
+
+// Run e-postaları interval aralıklarla gönderir, main'de goroutine olarak çalıştırılır
+func (w *Worker) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := w.SendPending(); err != nil {
+			log.Println("email send error:", err)
+		}
+		if err := w.SendDigests(time.Now().UTC()); err != nil {
+			log.Println("email digest error:", err)
+		}
+	}
+}
+
+// claimable gönderilmeyi bekleyen ve başka instance tarafından alınmamış e-postalar
+func claimable(tx *gorm.DB) *gorm.DB {
+	return tx.Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", models.DeliveryPending, time.Now())
+}
+
+// claim e-postaları lease süresince başka instance'lara kapatır
+func claim(tx *gorm.DB, notifications []models.EmailNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		ids = append(ids, n.ID)
+	}
+	return tx.Model(&models.EmailNotification{}).Where("id IN ?", ids).
+		Update("next_attempt_at", time.Now().Add(claimLease)).Error
+}
+
+// SendPending digest kullanmayan kullanıcıların bekleyen e-postalarını tek tek gönderir.
+// Satırlar kısa bir transaction'da alınır, SMTP gönderimi commit'ten sonra yapılır; böylece
+// yavaş SMTP sunucusu satır kilitlerini tutmaz ve birden fazla instance aynı e-postayı göndermez.
+func (w *Worker) SendPending() error {
+	var pending []models.EmailNotification
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := claimable(tx).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("user_id IN (?)", tx.Model(&models.User{}).Select("id").Where("NOT email_digest")).
+			Order("id").Limit(batchSize).Find(&pending).Error; err != nil {
+			return err
+		}
+		return claim(tx, pending)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, n := range pending {
+		var user models.User
+		if err := w.DB.First(&user, n.UserID).Error; err != nil {
+			w.finish(w.DB, []models.EmailNotification{n}, models.DeliveryFailed, "user not found")
+			continue
+		}
+
+		item, err := w.item(w.DB, n)
+		if err == nil {
+			var msg Message
+			if msg, err = Render(user.Language, "notification", user.Email, map[string]any{"User": user, "Item": item}); err == nil {
+				err = w.Mailer.Send(msg)
+			}
+		}
+		w.result(w.DB, []models.EmailNotification{n}, err)
+	}
+	return nil
+}
+
+// SendDigests günün özet saati geçtiyse, bugün özet almamış kullanıcılara bekleyen e-postalarını tek e-postada gönderir
+func (w *Worker) SendDigests(now time.Time) error {
+	digestAt := time.Date(now.Year(), now.Month(), now.Day(), w.DigestHour, 0, 0, 0, time.UTC)
+	if now.Before(digestAt) {
+		return nil
+	}
+
+	for {
+		sent, err := w.sendNextDigest(digestAt)
+		if err != nil || !sent {
+			return err
+		}
+	}
+}
+
+// sendNextDigest sıradaki kullanıcının özetini gönderir, kimse kalmadıysa false döner.
+// Kullanıcı ve e-postaları transaction'da alınır, gönderim commit'ten sonra yapılır.
+func (w *Worker) sendNextDigest(digestAt time.Time) (bool, error) {
+	var user models.User
+	var pending []models.EmailNotification
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("email_digest AND (last_digest_at IS NULL OR last_digest_at < ?)", digestAt).
+			Where("id IN (?)", claimable(tx.Model(&models.EmailNotification{})).Select("user_id")).
+			Order("id").First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		if err := claimable(tx).Where("user_id = ?", user.ID).Order("id").Find(&pending).Error; err != nil {
+			return err
+		}
+		if err := claim(tx, pending); err != nil {
+			return err
+		}
+		// Hata olsa da bugünkü özet denendi sayılır, e-postalar bir sonraki özette tekrar denenir
+		return tx.Model(&user).Update("last_digest_at", time.Now()).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	items := make([]Item, 0, len(pending))
+	for _, n := range pending {
+		if item, err := w.item(w.DB, n); err == nil {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		w.finish(w.DB, pending, models.DeliveryFailed, "task not found")
+		return true, nil
+	}
+
+	msg, err := Render(user.Language, "digest", user.Email, map[string]any{"User": user, "Items": items})
+	if err == nil {
+		err = w.Mailer.Send(msg)
+	}
+	w.result(w.DB, pending, err)
+	return true, nil
+}
+
+// item bildirimin e-postada gösterilecek bilgilerini yükler; task silinmiş olsa da gösterilir
+func (w *Worker) item(tx *gorm.DB, n models.EmailNotification) (Item, error) {
+	var task models.Task
+	if err := tx.Unscoped().First(&task, n.TaskID).Error; err != nil {
+		return Item{}, err
+	}
+	var board models.Board
+	tx.Unscoped().Select("id", "title").First(&board, task.BoardID)
+	var actor models.User
+	tx.Select("id", "name").First(&actor, n.ActorID)
+
+	item := Item{
+		Type:       n.Type,
+		ActorName:  actor.Name,
+		TaskTitle:  task.Title,
+		BoardTitle: board.Title,
+		TaskURL:    fmt.Sprintf("%s/tasks/%d", w.AppURL, task.ID),
+	}
+	if task.DueAt != nil {
+		item.DueAt = task.DueAt.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	if n.CommentID != nil {
+		var comment models.Comment
+		if tx.Select("body").First(&comment, *n.CommentID).Error == nil {
+			item.CommentBody = comment.Body
+			if len([]rune(item.CommentBody)) > maxCommentLength {
+				item.CommentBody = string([]rune(item.CommentBody)[:maxCommentLength]) + "…"
+			}
+		}
+	}
+	return item, nil
+}
+
+// result gönderim sonucunu yazar; hata varsa deneme hakkı bitene kadar pending kalır
+func (w *Worker) result(tx *gorm.DB, notifications []models.EmailNotification, sendErr error) {
+	if sendErr == nil {
+		w.finish(tx, notifications, models.DeliverySuccess, "")
+		return
+	}
+	log.Println("email delivery error:", sendErr)
+
+	for _, n := range notifications {
+		status := models.DeliveryPending
+		if n.Attempts+1 >= maxAttempts {
+			status = models.DeliveryFailed
+		}
+		if err := tx.Model(&n).Updates(map[string]any{
+			"status": status, "attempts": n.Attempts + 1, "error": sendErr.Error(), "next_attempt_at": nil,
+		}).Error; err != nil {
+			log.Println("email status update error:", err)
+		}
+	}
+}
+
+func (w *Worker) finish(tx *gorm.DB, notifications []models.EmailNotification, status, reason string) {
+	ids := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		ids = append(ids, n.ID)
+	}
+
+	updates := map[string]any{"status": status, "error": reason}
+	if status == models.DeliverySuccess {
+		updates["sent_at"] = time.Now()
+	}
+	if err := tx.Model(&models.EmailNotification{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+		log.Println("email status update error:", err)
+	}
+}
@@ -1,18 +1,17 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/ahmetcanc/TaskMan/internal/auth"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware oturum JWT'sini ya da kişisel API token'ını doğrular
+func JWTAuthMiddleware(db *gorm.DB, rdb *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -30,48 +29,49 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenMalformed
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil {
-			fmt.Println("Parse error:", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+		if auth.IsPersonalToken(tokenString) {
+			personalTokenAuth(c, db, tokenString)
 			return
 		}
 
-		if token == nil || !token.Valid {
+		claims, err := auth.ParseAccessToken(tokenString)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		// Oturum logout / şifre değişikliği / admin tarafından kapatıldıysa token geçersiz
+		active, err := auth.SessionActive(c.Request.Context(), rdb, claims.SessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Session check failed"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		if !active {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked"})
 			c.Abort()
 			return
 		}
 
-		userIDFloat, ok := claims["user_id"].(float64)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
-			c.Abort()
-			return
-		}
+		c.Set("user_id", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("org_id", claims.OrganizationID)
+		c.Set("session_id", claims.SessionID)
+		c.Set("email_verified", !claims.EmailUnverified)
 
-		c.Set("user_id", uint(userIDFloat))
+		c.Next()
+	}
+}
 
+// TokenFromQuery tarayıcının header gönderemediği bağlantılar (WebSocket, EventSource) için
+// ?access_token= değerini Authorization header'ına taşır. JWTAuthMiddleware'den önce kullanılmalı.
+// Değer request log'unda Logger tarafından gizlenir.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
 		c.Next()
 	}
 }
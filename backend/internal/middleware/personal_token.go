@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/auth"
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Her istekte DB'ye yazmamak için son kullanım en fazla bu sıklıkta güncellenir
+const tokenLastUsedInterval = time.Minute
+
+// personalTokenAuth kişisel API token'ını doğrular; oturum yerine token'ın workspace'i ve yetkileri context'e konur
+func personalTokenAuth(c *gin.Context, db *gorm.DB, tokenString string) {
+	var token models.PersonalAccessToken
+	err := db.Preload("User").Where("token_hash = ?", auth.HashPersonalToken(tokenString)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && token.User == nil) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token check failed"})
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	// Token workspace'ten çıkarılınca ya da workspace 2FA isteyip kullanıcıda yoksa çalışmaz
+	var org models.Organization
+	err = db.Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organizations.id = ? AND organization_members.user_id = ?", token.OrganizationID, token.UserID).
+		First(&org).Error
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token workspace is no longer accessible"})
+		c.Abort()
+		return
+	}
+	if org.RequireTwoFactor && token.User.TOTPEnabledAt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Workspace requires two-factor authentication"})
+		c.Abort()
+		return
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= tokenLastUsedInterval {
+		db.Model(&token).UpdateColumns(map[string]any{"last_used_at": now, "last_used_ip": c.ClientIP()})
+	}
+
+	c.Set("user_id", token.UserID)
+	c.Set("role", token.User.Role)
+	c.Set("org_id", token.OrganizationID)
+	c.Set("email_verified", token.User.EmailVerifiedAt != nil)
+	c.Set("token_id", token.ID)
+	c.Set("token_scopes", token.Scopes)
+
+	c.Next()
+}
+
+// RequireScope kişisel API token'ıyla gelen isteklerde token'ın yetkisini kontrol eder, oturumlar etkilenmez.
+// JWTAuthMiddleware'den sonra kullanılmalı.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scopes, ok := c.Get("token_scopes"); ok && !models.ScopeAllows(scopes.(string), scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing scope " + scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession hesap ve oturum işlemlerini (şifre, 2FA, token yönetimi...) kişisel API token'larına kapatır.
+// JWTAuthMiddleware'den sonra kullanılmalı.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("session_id") == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not available with API tokens"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func hasRole(c *gin.Context, roles []string) bool {
+	role := c.GetString("role")
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole sadece verilen rollere sahip kullanıcıların geçmesine izin verir.
+// JWTAuthMiddleware'den sonra kullanılmalı.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasRole(c, roles) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail e-postasını doğrulamamış kullanıcıların değişiklik yapmasını engeller.
+// JWTAuthMiddleware'den sonra kullanılmalı.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("email_verified") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrRole URL'deki :param kullanıcının kendi ID'si ise ya da
+// kullanıcı verilen rollerden birine sahipse geçişe izin verir.
+func RequireSelfOrRole(param string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param(param), 10, 64)
+		if err == nil && uint(id) == c.GetUint("user_id") {
+			c.Next()
+			return
+		}
+
+		if !hasRole(c, roles) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
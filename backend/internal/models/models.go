@@ -1,28 +1,251 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kullanıcı rolleri
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleGuest  = "guest"
+)
+
+// ValidRole rolün tanımlı olup olmadığını kontrol eder
+func ValidRole(role string) bool {
+	return role == RoleAdmin || role == RoleMember || role == RoleGuest
+}
 
 // User tablosu
 type User struct {
+	ID       uint   `gorm:"primaryKey"`
+	Name     string `gorm:"size:100;not null"`
+	Email    string `gorm:"uniqueIndex;size:150;not null"`
+	Password string `gorm:"not null" json:"-"`
+	Role     string `gorm:"size:20;not null;default:'member'"` // admin, member, guest
+	Language string `gorm:"size:10;not null;default:'en'"`     // e-posta dili, bkz. mail.Languages
+	// Bildirim e-postaları tek tek değil günlük özet olarak gönderilir
+	EmailDigest  bool       `gorm:"not null;default:false"`
+	LastDigestAt *time.Time `json:"-"`
+	// Doğrulanmamış hesaplar sadece okuma yapabilir
+	EmailVerifiedAt *time.Time
+	// İki adımlı doğrulama (TOTP); secret sadece etkinleştirildikten sonra yazılır
+	TOTPSecret    string `gorm:"size:64" json:"-"`
+	TOTPEnabledAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Boards []Board
+}
+
+// RecoveryCode tablosu - TOTP cihazı kaybolursa girişte kullanılan tek kullanımlık kodlar, sadece hash'i tutulur
+type RecoveryCode struct {
 	ID        uint   `gorm:"primaryKey"`
-	Name      string `gorm:"size:100;not null"`
-	Email     string `gorm:"uniqueIndex;size:150;not null"`
-	Password  string `gorm:"not null"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
 
-	Boards []Board
+// UserIdentity tablosu - SSO (OIDC) hesabının kullanıcıya bağlantısı; IdP'de e-posta değişse de issuer + subject sabittir
+type UserIdentity struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"not null;index"`
+	Issuer      string `gorm:"size:255;not null;uniqueIndex:idx_user_identity"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_user_identity"`
+	Email       string `gorm:"size:150"` // IdP'nin son bildirdiği e-posta
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+}
+
+// Kişisel API token yetkileri; write:x read:x'i de kapsar
+const (
+	ScopeReadBoards         = "read:boards"
+	ScopeWriteBoards        = "write:boards"
+	ScopeReadTasks          = "read:tasks"
+	ScopeWriteTasks         = "write:tasks"
+	ScopeReadNotifications  = "read:notifications"
+	ScopeWriteNotifications = "write:notifications"
+	ScopeReadOrganizations  = "read:organizations"
+	ScopeWriteOrganizations = "write:organizations"
+	ScopeWebhooks           = "webhooks"
+	ScopeAdmin              = "admin" // /users, /audit gibi admin endpoint'leri, sadece admin'ler alabilir
+)
+
+var Scopes = []string{
+	ScopeReadBoards, ScopeWriteBoards, ScopeReadTasks, ScopeWriteTasks,
+	ScopeReadNotifications, ScopeWriteNotifications, ScopeReadOrganizations, ScopeWriteOrganizations,
+	ScopeWebhooks, ScopeAdmin,
+}
+
+// ValidScope yetkinin tanımlı olup olmadığını kontrol eder
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeAllows virgülle ayrılmış yetki listesi required'ı kapsıyorsa true döner
+func ScopeAllows(scopes, required string) bool {
+	write := strings.Replace(required, "read:", "write:", 1)
+	for _, s := range strings.Split(scopes, ",") {
+		if s == required || s == write {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessToken tablosu - script ve CI için kişisel API token'ı, sadece hash'i tutulur.
+// Oluşturulduğu workspace'te, sahibinin güncel rolüyle ve yetkileriyle sınırlı çalışır.
+type PersonalAccessToken struct {
+	ID             uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"not null;index"`
+	OrganizationID uint   `gorm:"not null"`
+	Name           string `gorm:"size:100;not null"`
+	TokenHash      string `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Hint           string `gorm:"size:20;not null"`  // listede tanımak için token'ın son karakterleri
+	Scopes         string `gorm:"size:500;not null"` // virgülle ayrılmış, ör. "read:tasks,write:tasks"
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+	LastUsedIP     string `gorm:"size:64"`
+	CreatedAt      time.Time
+
+	User *User `json:"-"`
+}
+
+// Organization tablosu - board'ları ve üyeleri barındıran workspace (tenant)
+type Organization struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:150;not null"`
+	// Üyeler 2FA açmadan bu workspace'e geçemez
+	RequireTwoFactor bool `gorm:"not null;default:false"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Workspace üyelik rolleri
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+var orgRoleRank = map[string]int{
+	OrgRoleMember: 1,
+	OrgRoleAdmin:  2,
+	OrgRoleOwner:  3,
+}
+
+// ValidOrgRole rolün tanımlı olup olmadığını kontrol eder
+func ValidOrgRole(role string) bool {
+	_, ok := orgRoleRank[role]
+	return ok
+}
+
+// OrgRoleAllows role en az required kadar yetkiliyse true döner
+func OrgRoleAllows(role, required string) bool {
+	return orgRoleRank[role] >= orgRoleRank[required]
+}
+
+// OrganizationMember tablosu
+type OrganizationMember struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;uniqueIndex:idx_org_member"`
+	UserID         uint   `gorm:"not null;uniqueIndex:idx_org_member;index"`
+	Role           string `gorm:"size:20;not null;default:'member'"` // owner, admin, member
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	User         *User         `json:",omitempty"`
+	Organization *Organization `json:",omitempty"`
 }
 
 // Board tablosu
 type Board struct {
+	ID             uint   `gorm:"primaryKey"`
+	Title          string `gorm:"size:150;not null"`
+	UserID         uint   `gorm:"not null;index"`
+	OrganizationID uint   `gorm:"index"`
+	// Açık blocker'ı olan task tamamlanmış kolona taşınamaz
+	EnforceDependencies bool `gorm:"not null;default:false"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"` // çöp kutusunda, bkz. GET /trash
+
+	Tasks   []Task
+	Columns []BoardColumn `json:",omitempty"`
+}
+
+// Yeni board'lar için varsayılan workflow
+var DefaultColumns = []string{"todo", "in-progress", "done"}
+
+// Varsayılan workflow'da tamamlanmış sayılan kolon
+const DefaultDoneColumn = "done"
+
+// BoardColumn tablosu - board'un sıralı workflow kolonları (status'ler)
+type BoardColumn struct {
 	ID        uint   `gorm:"primaryKey"`
-	Title     string `gorm:"size:150;not null"`
-	UserID    uint   `gorm:"not null;index"`
+	BoardID   uint   `gorm:"not null;uniqueIndex:idx_board_column_name"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_board_column_name"`
+	Position  int    `gorm:"not null;default:0"`
+	WIPLimit  int    `gorm:"not null;default:0"`     // 0 = limitsiz
+	Done      bool   `gorm:"not null;default:false"` // bu kolondaki task'lar tamamlanmış sayılır
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ColumnTransition tablosu - kolonlar arası izin verilen geçişler.
+// Board'da hiç geçiş tanımlı değilse tüm geçişler serbesttir.
+type ColumnTransition struct {
+	ID           uint `gorm:"primaryKey"`
+	BoardID      uint `gorm:"not null;index"`
+	FromColumnID uint `gorm:"not null"`
+	ToColumnID   uint `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+// Board üyelik rolleri
+const (
+	BoardRoleOwner  = "owner"
+	BoardRoleEditor = "editor"
+	BoardRoleViewer = "viewer"
+)
+
+var boardRoleRank = map[string]int{
+	BoardRoleViewer: 1,
+	BoardRoleEditor: 2,
+	BoardRoleOwner:  3,
+}
+
+// ValidBoardRole rolün tanımlı olup olmadığını kontrol eder
+func ValidBoardRole(role string) bool {
+	_, ok := boardRoleRank[role]
+	return ok
+}
+
+// BoardRoleAllows role en az required kadar yetkiliyse true döner
+func BoardRoleAllows(role, required string) bool {
+	return boardRoleRank[role] >= boardRoleRank[required]
+}
+
+// BoardMember tablosu - board paylaşımı
+type BoardMember struct {
+	ID        uint   `gorm:"primaryKey"`
+	BoardID   uint   `gorm:"not null;uniqueIndex:idx_board_member"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_board_member;index"`
+	Role      string `gorm:"size:20;not null;default:'viewer'"` // owner, editor, viewer
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Tasks []Task
+	User *User `json:",omitempty"`
 }
 
 // Task tablosu
@@ -30,8 +253,294 @@ type Task struct {
 	ID          uint   `gorm:"primaryKey"`
 	Title       string `gorm:"size:150;not null"`
 	Description string `gorm:"type:text"`
-	Status      string `gorm:"size:50;default:'todo'"` // todo, in-progress, done
-	BoardID     uint   `gorm:"not null;index"`
+	Status      string `gorm:"size:50;default:'todo'"` // board'un BoardColumn adlarından biri
+	Rank        string `gorm:"size:255;index"`         // kolon içi sıra, bkz. internal/rank
+	Priority    string `gorm:"size:20;not null;default:'medium'"`
+	StartAt     *time.Time
+	DueAt       *time.Time `gorm:"index"`
+	BoardID     uint       `gorm:"not null;index"`
+	ParentID    *uint      `gorm:"index"`                           // alt task ise üst task, aynı board'da olmalı
+	DueNotified bool       `gorm:"not null;default:false" json:"-"` // yaklaşan bitiş bildirimi gönderildi
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"` // board ile silinen task'lar board'un DeletedAt'ini alır
+
+	Assignees []User  `gorm:"many2many:task_assignees" json:",omitempty"`
+	Labels    []Label `gorm:"many2many:task_labels" json:",omitempty"`
+
+	// Checklist ve alt task'lardan hesaplanır, DB'de tutulmaz
+	Progress *TaskProgress `gorm:"-" json:",omitempty"`
+
+	// Sadece GET /tasks/:id'de doldurulur
+	Blockers   []Task `gorm:"-" json:",omitempty"`
+	Dependents []Task `gorm:"-" json:",omitempty"`
+}
+
+// TaskDependency tablosu - BlockerID tamamlanmadan BlockedID bitirilemez
+type TaskDependency struct {
+	ID        uint `gorm:"primaryKey"`
+	BlockerID uint `gorm:"not null;uniqueIndex:idx_task_dependency;index"`
+	BlockedID uint `gorm:"not null;uniqueIndex:idx_task_dependency"`
+	UserID    uint `gorm:"not null"` // bağımlılığı ekleyen
+	CreatedAt time.Time
+}
+
+// TaskProgress tamamlanan checklist maddesi + alt task sayısı, ör. "3/5 done"
+type TaskProgress struct {
+	Done  int
+	Total int
+	Text  string
+}
+
+// ChecklistItem tablosu - task içindeki sıralı, işaretlenebilir madde
+type ChecklistItem struct {
+	ID         uint   `gorm:"primaryKey"`
+	TaskID     uint   `gorm:"not null;index"`
+	Title      string `gorm:"size:255;not null"`
+	Done       bool   `gorm:"not null;default:false"`
+	Position   int    `gorm:"not null;default:0"`
+	AssigneeID *uint
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	Assignee *User `json:",omitempty"`
+}
+
+// Label tablosu - board'a ait renkli etiket kataloğu
+type Label struct {
+	ID        uint   `gorm:"primaryKey"`
+	BoardID   uint   `gorm:"not null;uniqueIndex:idx_board_label_name"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_board_label_name"`
+	Color     string `gorm:"size:7;not null;default:'#808080'"` // #RRGGBB
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Task öncelikleri (düşükten yükseğe)
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// Priorities sıralama için düşükten yükseğe
+var Priorities = []string{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// ValidPriority önceliğin tanımlı olup olmadığını kontrol eder
+func ValidPriority(priority string) bool {
+	for _, p := range Priorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// Comment tablosu - task üzerindeki yorum
+type Comment struct {
+	ID        uint   `gorm:"primaryKey"`
+	TaskID    uint   `gorm:"not null;index"`
+	UserID    uint   `gorm:"not null;index"`
+	Body      string `gorm:"type:text;not null"`
+	EditedAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User *User `json:",omitempty"`
+}
+
+// CommentRevision yorum düzenlenmeden önceki metni saklar
+type CommentRevision struct {
+	ID        uint   `gorm:"primaryKey"`
+	CommentID uint   `gorm:"not null;index"`
+	Body      string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+// Bildirim tipleri
+const (
+	NotificationMention  = "mention"
+	NotificationAssigned = "assigned"
+	NotificationWatch    = "watch" // izlenen task'ta değişiklik
+	NotificationDueSoon  = "due_soon"
+)
+
+// NotificationTypes tercihleri ayarlanabilen bildirim tipleri
+var NotificationTypes = []string{NotificationMention, NotificationAssigned, NotificationWatch, NotificationDueSoon}
+
+// EmailNotificationTypes e-posta ile de gönderilebilen bildirim tipleri
+var EmailNotificationTypes = []string{NotificationMention, NotificationAssigned, NotificationDueSoon}
+
+// Bildirim kanalları
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+var NotificationChannels = []string{ChannelInApp, ChannelEmail}
+
+// Notification tablosu - kullanıcıya giden bildirim kaydı
+type Notification struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index:idx_notification_user_read"` // bildirimi alan
+	ActorID   uint       `gorm:"not null"`                                  // bildirime sebep olan
+	Type      string     `gorm:"size:50;not null"`
+	Event     string     `gorm:"size:100"` // watch bildirimlerinde değişiklik, ör. comment.created
+	TaskID    uint       `gorm:"index"`
+	CommentID *uint      `gorm:"index"`
+	ReadAt    *time.Time `gorm:"index:idx_notification_user_read"`
+	CreatedAt time.Time
+
+	Actor *User `gorm:"foreignKey:ActorID" json:",omitempty"`
+}
+
+// NotificationPreference tablosu - kayıt yoksa bildirim açıktır
+type NotificationPreference struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_notification_preference"`
+	Type      string `gorm:"size:50;not null;uniqueIndex:idx_notification_preference"`
+	Channel   string `gorm:"size:20;not null;uniqueIndex:idx_notification_preference"`
+	Enabled   bool   `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TaskWatcher tablosu - task'taki değişikliklerden bildirim alan kullanıcılar
+type TaskWatcher struct {
+	ID        uint `gorm:"primaryKey"`
+	TaskID    uint `gorm:"not null;uniqueIndex:idx_task_watcher"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_task_watcher;index"`
+	CreatedAt time.Time
+}
+
+// Attachment tablosu - task'a eklenen dosyanın metadata'sı, içerik storage'da durur
+type Attachment struct {
+	ID          uint   `gorm:"primaryKey"`
+	TaskID      uint   `gorm:"not null;index"`
+	UserID      uint   `gorm:"not null"`
+	FileName    string `gorm:"size:255;not null"`
+	ContentType string `gorm:"size:100;not null"`
+	Size        int64  `gorm:"not null"`
+	StorageKey  string `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time
+}
+
+// Audit işlemleri
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// AuditEvent tablosu - değiştirilemez işlem kaydı, alan bazında önce/sonra farkı tutar
+type AuditEvent struct {
+	ID             uint            `gorm:"primaryKey"`
+	ActorID        uint            `gorm:"index"` // işlemi yapan kullanıcı
+	Action         string          `gorm:"size:20;not null"`
+	EntityType     string          `gorm:"size:50;not null;index:idx_audit_entity"`
+	EntityID       uint            `gorm:"not null;index:idx_audit_entity"`
+	OrganizationID *uint           `gorm:"index"` // kaydın ait olduğu workspace, /audit bununla sınırlanır
+	BoardID        *uint           `gorm:"index"`
+	TaskID         *uint           `gorm:"index"`
+	Changes        json.RawMessage `gorm:"type:jsonb"` // {"Status": {"before": "todo", "after": "done"}}
+	RequestID      string          `gorm:"size:64;index"`
+	CreatedAt      time.Time       `gorm:"index"`
+}
+
+// Güvenlik event tipleri
+const (
+	SecurityAccountLocked   = "account_locked"
+	SecurityIPLocked        = "ip_locked"
+	SecurityAccountUnlocked = "account_unlocked"
+)
+
+// SecurityEvent tablosu - kilitlenme gibi güvenlik olayları; hesap olmayabilir (ör. IP kilidi)
+type SecurityEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	Type      string    `gorm:"size:50;not null;index"`
+	UserID    *uint     `gorm:"index"` // olayın ilgili olduğu hesap
+	ActorID   *uint     // olayı tetikleyen admin (ör. kilit açma)
+	Email     string    `gorm:"size:150"` // girişte denenen e-posta
+	IP        string    `gorm:"size:64;index"`
+	RequestID string    `gorm:"size:64"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// Webhook tablosu - workspace'e ya da tek bir board'a ait dış endpoint
+type Webhook struct {
+	ID             uint   `gorm:"primaryKey"`
+	OrganizationID uint   `gorm:"not null;index"`
+	BoardID        *uint  `gorm:"index"`    // nil ise workspace'teki tüm board'lar
+	UserID         uint   `gorm:"not null"` // oluşturan
+	URL            string `gorm:"size:500;not null"`
+	Secret         string `gorm:"size:64;not null" json:"-"` // HMAC-SHA256 imza anahtarı
+	Events         string `gorm:"size:1000;not null"`        // virgülle ayrılmış event tipleri, "*" hepsi
+	Active         bool   `gorm:"not null;default:true"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Webhook teslimat durumları
+const (
+	DeliveryPending = "pending"
+	DeliverySuccess = "success"
+	DeliveryFailed  = "failed" // deneme hakkı bitti
+)
+
+// WebhookDelivery tablosu - bir event'in bir webhook'a gönderimi ve son denemenin sonucu
+type WebhookDelivery struct {
+	ID            uint            `gorm:"primaryKey"`
+	WebhookID     uint            `gorm:"not null;index"`
+	EventType     string          `gorm:"size:100;not null"`
+	Payload       json.RawMessage `gorm:"type:jsonb;not null"`
+	Status        string          `gorm:"size:20;not null;default:'pending';index:idx_delivery_due"`
+	Attempts      int             `gorm:"not null;default:0"`
+	NextAttemptAt time.Time       `gorm:"index:idx_delivery_due"`
+	ResponseCode  int
+	ResponseBody  string `gorm:"type:text"` // ilk 2 KB
+	Error         string `gorm:"type:text"`
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// OutboxEvent tablosu - değişiklikle aynı transaction'da yazılan domain event'i, relay tarafından yayınlanır
+type OutboxEvent struct {
+	ID uint `gorm:"primaryKey;index:idx_outbox_position,priority:2"`
+	// Event'i yazan transaction'ın ID'si (pg_current_xact_id); relay sadece bitmiş transaction'ların event'lerini okur
+	TxID           int64           `gorm:"not null;default:0;index:idx_outbox_position,priority:1"`
+	Type           string          `gorm:"size:100;not null"` // ör. task.updated
+	OrganizationID uint            `gorm:"index"`
+	BoardID        uint            `gorm:"index"` // board dışı event'lerde 0
+	EntityType     string          `gorm:"size:50;not null"`
+	EntityID       uint            `gorm:"not null"`
+	Payload        json.RawMessage `gorm:"type:jsonb;not null"` // realtime.Event
+	CreatedAt      time.Time       `gorm:"index"`
+}
+
+// OutboxOffset her consumer'ın işlediği son event'in (TxID, ID) konumu
+type OutboxOffset struct {
+	Consumer  string `gorm:"primaryKey;size:50"`
+	LastTxID  int64  `gorm:"not null;default:0"`
+	LastID    uint   `gorm:"not null;default:0"`
+	UpdatedAt time.Time
+}
+
+// EmailNotification tablosu - gönderilecek bildirim e-postası; digest kullanıcıları için günlük toplanır
+type EmailNotification struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	ActorID   uint   `gorm:"not null"`
+	Type      string `gorm:"size:50;not null"`
+	TaskID    uint
+	CommentID *uint
+	Status    string `gorm:"size:20;not null;default:'pending';index"` // DeliveryPending, DeliverySuccess, DeliveryFailed
+	Attempts  int    `gorm:"not null;default:0"`
+	Error     string `gorm:"type:text"`
+	// Gönderim için alınan e-posta bu zamana kadar başka instance'a verilmez
+	NextAttemptAt *time.Time
+	SentAt        *time.Time
+	CreatedAt     time.Time
 }
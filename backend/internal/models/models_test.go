@@ -0,0 +1,47 @@
+package models
+
+import "testing"
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		scopes   string
+		required string
+		want     bool
+	}{
+		{"read:tasks", ScopeReadTasks, true},
+		// write yetkisi aynı kaynağın read yetkisini kapsar, tersi geçerli değil
+		{"write:tasks", ScopeReadTasks, true},
+		{"read:tasks", ScopeWriteTasks, false},
+		{"write:tasks", ScopeWriteTasks, true},
+		{"write:boards", ScopeReadTasks, false},
+		{"read:boards,write:tasks", ScopeReadTasks, true},
+		{"read:boards,write:tasks", ScopeWriteBoards, false},
+		// admin sadece admin endpoint'lerini açar, diğer yetkileri kapsamaz
+		{"admin", ScopeAdmin, true},
+		{"admin", ScopeReadTasks, false},
+		{"write:tasks,webhooks", ScopeAdmin, false},
+		{"webhooks", ScopeWebhooks, true},
+		{"", ScopeReadTasks, false},
+		// Tam eşleşme gerekir
+		{"read:task", ScopeReadTasks, false},
+		{"read:tasks:extra", ScopeReadTasks, false},
+	}
+	for _, tt := range tests {
+		if got := ScopeAllows(tt.scopes, tt.required); got != tt.want {
+			t.Errorf("ScopeAllows(%q, %q) = %v, want %v", tt.scopes, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestValidScope(t *testing.T) {
+	for _, scope := range Scopes {
+		if !ValidScope(scope) {
+			t.Errorf("ValidScope(%q) = false", scope)
+		}
+	}
+	for _, scope := range []string{"", "read:everything", "write:", "ADMIN"} {
+		if ValidScope(scope) {
+			t.Errorf("ValidScope(%q) = true", scope)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrEmailMissing   = errors.New("email claim missing")
+)
+
+// Discovery ve JWKS bu kadar süre önbellekte tutulur, bilinmeyen kid gelirse daha önce yenilenir
+const metadataTTL = time.Hour
+
+// Config identity provider ayarları
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // frontend'in callback sayfası, IdP'de kayıtlı olmalı
+	Scopes       []string // openid her zaman eklenir
+	// false ise sadece e-postası TaskMan'de kayıtlı kullanıcılar giriş yapabilir
+	AutoProvision bool
+}
+
+// Claims ID token'dan (gerekirse userinfo'dan) alınan kullanıcı bilgileri
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+}
+
+// profileClaims ID token ve userinfo'da ortak alanlar; email_verified bazı IdP'lerde string ("true") gelir
+type profileClaims struct {
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Username      string `json:"preferred_username"`
+}
+
+type metadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Provider OIDC authorization code + PKCE akışı; metadata ilk kullanımda IdP'den alınır
+type Provider struct {
+	Config Config
+	Client *http.Client
+
+	mu        sync.Mutex
+	meta      *metadata
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// Connect OIDC_ISSUER ayarlıysa provider, değilse nil döner (SSO kapalı).
+// Lokal test için docker-compose'daki mock OIDC server kullanılabilir, bkz. README.
+func Connect() *Provider {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		log.Println("🔓 OIDC_ISSUER not set, single sign-on disabled")
+		return nil
+	}
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	if clientID == "" {
+		log.Println("⚠️ OIDC_CLIENT_ID not set, single sign-on disabled")
+		return nil
+	}
+
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if redirectURL == "" {
+		appURL := strings.TrimRight(os.Getenv("APP_URL"), "/")
+		if appURL == "" {
+			appURL = "http://localhost:5173"
+		}
+		redirectURL = appURL + "/auth/oidc/callback"
+	}
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	log.Printf("🔐 OIDC issuer: %s", issuer)
+	return NewProvider(Config{
+		Issuer:        issuer,
+		ClientID:      clientID,
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   redirectURL,
+		Scopes:        scopes,
+		AutoProvision: os.Getenv("OIDC_AUTO_PROVISION") != "false",
+	})
+}
+
+func NewProvider(config Config) *Provider {
+	if !slices.Contains(config.Scopes, "openid") {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+	return &Provider{Config: config, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// RandomString URL'de güvenle taşınabilen rastgele değer (state, nonce, PKCE verifier)
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge PKCE S256 challenge'ı
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL kullanıcının yönlendirileceği IdP giriş adresi
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.Config.ClientID)
+	params.Set("redirect_uri", p.Config.RedirectURL)
+	params.Set("scope", strings.Join(p.Config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange authorization code'u token'a çevirir, ID token'ı (imza, issuer, audience, süre, nonce) doğrular
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.Config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.Config.ClientID)
+
+	// Discovery'de belirtilmemişse varsayılan client_secret_basic'tir
+	basicAuth := p.Config.ClientSecret != "" &&
+		(len(meta.TokenAuthMethods) == 0 || slices.Contains(meta.TokenAuthMethods, "client_secret_basic"))
+	if p.Config.ClientSecret != "" && !basicAuth {
+		form.Set("client_secret", p.Config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basicAuth {
+		req.SetBasicAuth(url.QueryEscape(p.Config.ClientID), url.QueryEscape(p.Config.ClientSecret))
+	}
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	claims, err := p.verifyIDToken(ctx, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bazı IdP'ler e-postayı ID token'a koymaz, userinfo'dan alınır
+	if claims.Email == "" && meta.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err := p.userinfo(ctx, meta.UserinfoEndpoint, tokens.AccessToken, claims); err != nil {
+			return nil, err
+		}
+	}
+	if claims.Email == "" {
+		return nil, ErrEmailMissing
+	}
+	return claims, nil
+}
+
+type idTokenClaims struct {
+	profileClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+func emailVerified(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	// Birden fazla audience varsa token bu client'a verilmiş olmalı
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.Config.ClientID {
+		return nil, ErrInvalidIDToken
+	}
+	if claims.Subject == "" || claims.Nonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: emailVerified(claims.EmailVerified),
+		Name:          claims.Name,
+		Username:      claims.Username,
+	}, nil
+}
+
+func (p *Provider) userinfo(ctx context.Context, endpoint, accessToken string, claims *Claims) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info struct {
+		profileClaims
+		Subject string `json:"sub"`
+	}
+	if err := p.doJSON(req, &info); err != nil {
+		return fmt.Errorf("userinfo: %w", err)
+	}
+	// Userinfo başka bir kullanıcıya aitse kullanılmaz
+	if info.Subject != claims.Subject {
+		return ErrInvalidIDToken
+	}
+
+	claims.Email = info.Email
+	claims.EmailVerified = emailVerified(info.EmailVerified)
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+	if claims.Username == "" {
+		claims.Username = info.Username
+	}
+	return nil
+}
+
+// metadata discovery dokümanını önbellekten ya da IdP'den döner
+func (p *Provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil && time.Since(p.fetchedAt) < metadataTTL {
+		return p.meta, nil
+	}
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return p.meta, nil
+}
+
+// key ID token'ı imzalayan public key; bilinmeyen kid'de JWKS bir kez yeniden alınır (key rotation)
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	// Aynı bilinmeyen kid ile IdP'ye istek yağdırılmasın
+	if time.Since(p.fetchedAt) < 10*time.Second {
+		return nil, ErrInvalidIDToken
+	}
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, ErrInvalidIDToken
+}
+
+func (p *Provider) lookupKey(kid string) (any, bool) {
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok
+	}
+	// kid yoksa tek key varsa o kullanılır
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// refresh discovery ve JWKS'i yeniden alır, p.mu tutulurken çağrılır
+func (p *Provider) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	var meta metadata
+	if err := p.doJSON(req, &meta); err != nil {
+		return fmt.Errorf("discovery: %w", err)
+	}
+	if strings.TrimRight(meta.Issuer, "/") != p.Config.Issuer {
+		return fmt.Errorf("discovery: issuer mismatch %q", meta.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return errors.New("discovery: missing endpoints")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	var set jwks
+	if err := p.doJSON(req, &set); err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	p.meta = &meta
+	p.keys = set.publicKeys()
+	p.fetchedAt = time.Now()
+	return nil
+}
+
+func (p *Provider) doJSON(req *http.Request, v any) error {
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
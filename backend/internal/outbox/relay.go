@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	batchSize = 100
+	// Tüm consumer'ların işlediği event'ler bu süre sonunda silinir
+	retention = 24 * time.Hour
+)
+
+// ID'ler commit sırasına göre gelmez, bu yüzden event'ler yazan transaction'ın ID'sine göre okunur.
+// Sadece TxID'si en eski açık transaction'dan (snapshot xmin) küçük event'ler alınır: bu transaction'lar
+// commit ya da rollback olmuştur ve artık araya yeni event giremez. Uzun süren bir transaction
+// teslimatı geciktirir ama event kaybolmaz; rollback olan transaction'ın event'i zaten yoktur.
+const visibleEvents = "tx_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint"
+
+// Consumer outbox event'lerini sırayla işler. Handle hata dönerse offset ilerlemez ve
+// event bir sonraki turda tekrar gelir (at-least-once); bu yüzden Handle idempotent olmalı.
+// tx, offset ile aynı transaction'dır: DB'ye yazan consumer'lar için işlem tam olarak bir kez uygulanır.
+type Consumer struct {
+	Name   string
+	Handle func(tx *gorm.DB, e models.OutboxEvent) error
+}
+
+// Relay her consumer için kendi offset'inden itibaren event'leri işler
+type Relay struct {
+	DB        *gorm.DB
+	Consumers []Consumer
+}
+
+func NewRelay(db *gorm.DB, consumers ...Consumer) *Relay {
+	return &Relay{DB: db, Consumers: consumers}
+}
+
+// Run consumer'ları interval aralıklarla çalıştırır, main'de goroutine olarak çalıştırılır
+func (r *Relay) Run(interval time.Duration) {
+	for _, consumer := range r.Consumers {
+		if err := r.DB.FirstOrCreate(&models.OutboxOffset{}, models.OutboxOffset{Consumer: consumer.Name}).Error; err != nil {
+			log.Printf("outbox offset init error (%s): %v", consumer.Name, err)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for range ticker.C {
+		for _, consumer := range r.Consumers {
+			for {
+				n, err := r.Process(consumer)
+				if err != nil {
+					log.Printf("outbox consumer %s error: %v", consumer.Name, err)
+				}
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			if err := r.Cleanup(); err != nil {
+				log.Println("outbox cleanup error:", err)
+			}
+			lastCleanup = time.Now()
+		}
+	}
+}
+
+// Process consumer'ın bir sonraki event grubunu işler ve işlenen sayısını döner.
+// Offset satırı kilitlenir, böylece bir consumer'ı aynı anda tek instance işler.
+func (r *Relay) Process(consumer Consumer) (int, error) {
+	processed := 0
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var offset models.OutboxOffset
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("consumer = ?", consumer.Name).First(&offset).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // başka instance işliyor
+		}
+		if err != nil {
+			return err
+		}
+
+		var events []models.OutboxEvent
+		if err := tx.Where("(tx_id, id) > (?, ?)", offset.LastTxID, offset.LastID).Where(visibleEvents).
+			Order("tx_id, id").Limit(batchSize).Find(&events).Error; err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if err := consumer.Handle(tx, e); err != nil {
+				return err
+			}
+			offset.LastTxID, offset.LastID = e.TxID, e.ID
+			processed++
+		}
+
+		if processed == 0 {
+			return nil
+		}
+		return tx.Save(&offset).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return processed, nil
+}
+
+// Cleanup tüm consumer'ların işlediği eski event'leri siler
+func (r *Relay) Cleanup() error {
+	names := make([]string, 0, len(r.Consumers))
+	for _, consumer := range r.Consumers {
+		names = append(names, consumer.Name)
+	}
+
+	// En geride kalan consumer'ın konumu
+	var offsets []models.OutboxOffset
+	if err := r.DB.Where("consumer IN ?", names).Order("last_tx_id, last_id").Limit(1).
+		Find(&offsets).Error; err != nil || len(offsets) == 0 {
+		return err
+	}
+	return r.DB.Where("(tx_id, id) <= (?, ?) AND created_at < ?", offsets[0].LastTxID, offsets[0].LastID, time.Now().Add(-retention)).
+		Delete(&models.OutboxEvent{}).Error
+}
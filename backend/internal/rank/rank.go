@@ -0,0 +1,86 @@
+package rank
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Rank'ler base36 string'lerdir ve byte sırasına göre (COLLATE "C") sıralanır.
+// İki rank arasına her zaman yeni bir rank üretilebildiği için taşıma
+// işleminde kolonun geri kalanı yeniden numaralandırılmaz.
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var ErrInvalidRange = errors.New("rank: no rank between lower and upper bound")
+
+func digitValue(s string, i int) int {
+	return strings.IndexByte(digits, s[i])
+}
+
+// Between a < sonuç < b olacak şekilde yeni bir rank döner.
+// Boş a alt sınır yok, boş b üst sınır yok demektir.
+// Arada rank kalmadıysa ErrInvalidRange döner, çağıran kolonu Spread ile dengelemeli.
+func Between(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", ErrInvalidRange
+	}
+
+	var result []byte
+	bounded := b != ""
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = digitValue(a, i)
+		}
+		hi := base
+		if bounded {
+			// Sonuç b'nin önekine ulaştı: a ile b arasında anahtar kalmadı (ör. "a" ve "a0")
+			if i >= len(b) {
+				return "", ErrInvalidRange
+			}
+			hi = 0
+			if i < len(b) {
+				hi = digitValue(b, i)
+			}
+		}
+
+		if hi-lo > 1 {
+			return string(append(result, digits[(lo+hi)/2])), nil
+		}
+
+		result = append(result, digits[lo])
+		// Bu basamakta b'den küçük kaldıysak üst sınır artık bağlayıcı değil
+		if hi > lo {
+			bounded = false
+		}
+	}
+}
+
+// Spread n eleman için eşit aralıklı rank'ler üretir (ilk sıralama ve yeniden dengeleme).
+func Spread(n int) []string {
+	width := 1
+	space := big.NewInt(int64(base))
+	// Her iki rank arasında en az base kadar boşluk bırak
+	for new(big.Int).Div(space, big.NewInt(int64(n+1))).Cmp(big.NewInt(int64(base))) < 0 {
+		width++
+		space.Mul(space, big.NewInt(int64(base)))
+	}
+
+	step := new(big.Int).Div(space, big.NewInt(int64(n+1)))
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := new(big.Int).Mul(step, big.NewInt(int64(i+1)))
+		s := strings.TrimRight(leftPad(value.Text(base), width), "0")
+		ranks[i] = s
+	}
+	return ranks
+}
+
+func leftPad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
@@ -0,0 +1,105 @@
+package rank
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"empty column", "", ""},
+		{"at start", "", "i"},
+		{"at end", "i", ""},
+		{"after last digit", "z", ""},
+		{"after zzz", "zzz", ""},
+		{"before smallest digit", "", "1"},
+		{"wide gap", "a", "z"},
+		{"adjacent digits", "a", "b"},
+		{"adjacent with suffix", "a", "a1"},
+		{"prefix of upper", "a", "ab"},
+		{"longer lower", "ay", "b"},
+		{"different lengths", "0i", "1"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("%s: Between(%q, %q) error: %v", tt.name, tt.a, tt.b, err)
+			continue
+		}
+		if got <= tt.a || (tt.b != "" && got >= tt.b) {
+			t.Errorf("%s: Between(%q, %q) = %q, not in range", tt.name, tt.a, tt.b, got)
+		}
+		// Sonu 0 olan rank'in önüne yeni rank sığmayabilir
+		if strings.HasSuffix(got, "0") {
+			t.Errorf("%s: Between(%q, %q) = %q ends with 0", tt.name, tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"reversed", "b", "a"},
+		{"equal", "a", "a"},
+		// Arada hiçbir string yok: sonuç ya a'ya eşit ya da b'den büyük olur
+		{"exhausted", "a", "a0"},
+		{"exhausted with zeros", "a", "a00"},
+		{"nothing before zero", "", "0"},
+	}
+	for _, tt := range tests {
+		if got, err := Between(tt.a, tt.b); err != ErrInvalidRange {
+			t.Errorf("%s: Between(%q, %q) = %q, %v, want ErrInvalidRange", tt.name, tt.a, tt.b, got, err)
+		}
+	}
+}
+
+func TestBetweenRepeated(t *testing.T) {
+	// Hep aynı komşunun altına eklemek rank'leri uzatır ama sırayı bozmaz
+	lo, hi := "a", "b"
+	for i := 0; i < 100; i++ {
+		got, err := Between(lo, hi)
+		if err != nil {
+			t.Fatalf("step %d: Between(%q, %q): %v", i, lo, hi, err)
+		}
+		if got <= lo || got >= hi {
+			t.Fatalf("step %d: Between(%q, %q) = %q, not in range", i, lo, hi, got)
+		}
+		hi = got
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 35, 36, 100, 1295, 1296, 5000} {
+		ranks := Spread(n)
+		if len(ranks) != n {
+			t.Errorf("Spread(%d) returned %d ranks", n, len(ranks))
+			continue
+		}
+		for i, r := range ranks {
+			if r == "" || strings.HasSuffix(r, "0") {
+				t.Errorf("Spread(%d)[%d] = %q", n, i, r)
+			}
+			if i > 0 && ranks[i-1] >= r {
+				t.Errorf("Spread(%d): %q >= %q at %d", n, ranks[i-1], r, i)
+			}
+		}
+		// Dengelemeden sonra her iki komşunun arasına da rank sığmalı
+		for i := 0; i <= n; i++ {
+			var a, b string
+			if i > 0 {
+				a = ranks[i-1]
+			}
+			if i < n {
+				b = ranks[i]
+			}
+			if _, err := Between(a, b); err != nil {
+				t.Errorf("Spread(%d): Between(%q, %q): %v", n, a, b, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package realtime
+
+import "time"
+
+// Tüm instance'ların dinlediği Redis pub/sub kanalı, event'leri outbox relay'i yayınlar
+const Channel = "board_events"
+
+// Event bir board'daki değişiklik, ör. task.updated. Board'suz event'ler (BoardID 0)
+// sadece UserID'deki kullanıcıya gider, ör. workspace'ten çıkarılma.
+type Event struct {
+	Type     string    `json:"type"` // <entity>.<created|updated|deleted|restored>
+	BoardID  uint      `json:"board_id"`
+	OrgID    uint      `json:"org_id,omitempty"`
+	UserID   uint      `json:"user_id,omitempty"`
+	TaskID   uint      `json:"task_id,omitempty"`
+	EntityID uint      `json:"entity_id"`
+	ActorID  uint      `json:"actor_id"`
+	Data     any       `json:"data,omitempty"`
+	At       time.Time `json:"at"`
+}
@@ -0,0 +1,210 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Yavaş client'lar yayını bloklamasın, kuyruk dolarsa event düşürülür
+const clientBuffer = 64
+
+// Board erişimini değiştirebilen event'ler, gelince client'ların board listesi yenilenir
+var accessEvents = map[string]bool{
+	"board.created":        true,
+	"board.deleted":        true,
+	"board.restored":       true,
+	"board_member.created": true,
+	"board_member.updated": true,
+	"board_member.deleted": true,
+	// Kullanıcıya özel: workspace'ten çıkarılınca oradaki board'lar düşer
+	"organization_member.deleted": true,
+}
+
+// AccessFunc kullanıcının aktif workspace'te erişebildiği board ID'leri
+type AccessFunc func(userID, orgID uint) ([]uint, error)
+
+// Client bağlı bir WebSocket/SSE bağlantısı
+type Client struct {
+	UserID uint
+	OrgID  uint
+	Events chan Event
+
+	mu        sync.Mutex
+	requested map[uint]bool // boşsa erişilebilen tüm board'lar
+	boards    map[uint]bool
+}
+
+// Hub instance'taki client'ları tutar ve Redis'ten gelen event'leri dağıtır
+type Hub struct {
+	RDB    *redis.Client
+	Access AccessFunc
+
+	mu      sync.RWMutex
+	clients map[*Client]bool
+}
+
+func NewHub(rdb *redis.Client, access AccessFunc) *Hub {
+	return &Hub{
+		RDB:     rdb,
+		Access:  access,
+		clients: map[*Client]bool{},
+	}
+}
+
+// Register yeni client ekler; boardIDs boşsa erişilebilen tüm board'lara abone olur
+func (h *Hub) Register(userID, orgID uint, boardIDs []uint) (*Client, error) {
+	client := &Client{UserID: userID, OrgID: orgID, Events: make(chan Event, clientBuffer)}
+	if err := h.Subscribe(client, boardIDs); err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+	return client, nil
+}
+
+// Unregister client'ı kaldırır ve kanalını kapatır
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	if h.clients[client] {
+		delete(h.clients, client)
+		close(client.Events)
+	}
+	h.mu.Unlock()
+}
+
+// Subscribe client'ın board listesini değiştirir, erişilemeyen board'lar atlanır
+func (h *Hub) Subscribe(client *Client, boardIDs []uint) error {
+	client.mu.Lock()
+	client.requested = map[uint]bool{}
+	for _, id := range boardIDs {
+		client.requested[id] = true
+	}
+	client.mu.Unlock()
+	return h.refresh(client)
+}
+
+// Boards client'ın şu an abone olduğu board'lar
+func (c *Client) Boards() []uint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]uint, 0, len(c.boards))
+	for id := range c.boards {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (h *Hub) refresh(client *Client) error {
+	accessible, err := h.Access(client.UserID, client.OrgID)
+	if err != nil {
+		return err
+	}
+	client.setBoards(accessible)
+	return nil
+}
+
+func (c *Client) setBoards(accessible []uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.boards = map[uint]bool{}
+	for _, id := range accessible {
+		if len(c.requested) == 0 || c.requested[id] {
+			c.boards[id] = true
+		}
+	}
+}
+
+func (c *Client) watches(boardID uint) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.boards[boardID]
+}
+
+// Run Redis kanalını dinler, ctx bitene kadar çalışır (main'de goroutine olarak)
+func (h *Hub) Run(ctx context.Context) {
+	sub := h.RDB.Subscribe(ctx, Channel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		var e Event
+		if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+			log.Println("realtime event decode error:", err)
+			continue
+		}
+		h.dispatch(e)
+	}
+}
+
+// receives board'suz event'in bu client'a ait olup olmadığı
+func (c *Client) receives(e Event) bool {
+	return e.BoardID == 0 && e.UserID != 0 && e.UserID == c.UserID
+}
+
+// affectedBy erişim event'i client'ın board listesini değiştirebilir mi: aynı workspace ya da event'in
+// kendisiyle ilgili olduğu kullanıcı. Workspace'i olmayan (eski) event'lerde hepsi yenilenir.
+func (c *Client) affectedBy(e Event) bool {
+	return e.OrgID == 0 || e.OrgID == c.OrgID || (e.UserID != 0 && e.UserID == c.UserID)
+}
+
+func (h *Hub) dispatch(e Event) {
+	// Erişim yenilemesi DB'ye gider; client listesi kopyalanır, kilit bu sırada tutulmaz
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	// Aynı kullanıcının aynı workspace'teki bağlantıları için erişim bir kez sorgulanır
+	accessible := map[[2]uint][]uint{}
+	for _, client := range clients {
+		if e.BoardID == 0 && !client.receives(e) {
+			continue
+		}
+
+		// Üyelikten çıkarılan kullanıcı son event'i de alır, yeni eklenen ilkini alır
+		watched := client.watches(e.BoardID) || client.receives(e)
+		if accessEvents[e.Type] && client.affectedBy(e) {
+			key := [2]uint{client.UserID, client.OrgID}
+			if _, ok := accessible[key]; !ok {
+				ids, err := h.Access(client.UserID, client.OrgID)
+				if err != nil {
+					log.Println("realtime access refresh error:", err)
+				} else {
+					accessible[key] = ids
+				}
+			}
+			if ids, ok := accessible[key]; ok {
+				client.setBoards(ids)
+			}
+		}
+		if !watched && !client.watches(e.BoardID) {
+			continue
+		}
+
+		h.send(client, e)
+	}
+}
+
+// send client'ın kuyruğuna event ekler; Unregister kanalı kapattıysa gönderilmez
+func (h *Hub) send(client *Client, e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.clients[client] {
+		return
+	}
+
+	select {
+	case client.Events <- e:
+	default:
+		log.Printf("realtime: dropping %s for slow client (user %d)", e.Type, client.UserID)
+	}
+}
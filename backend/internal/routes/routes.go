@@ -3,15 +3,26 @@ package routes
 import (
 	"github.com/ahmetcanc/TaskMan/internal/handlers"
 	"github.com/ahmetcanc/TaskMan/internal/middleware"
+	"github.com/ahmetcanc/TaskMan/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
 // SetupRoutes tüm endpointleri ayarlar ve router döndürür
 func SetupRoutes(
 	r *gin.Engine,
+	db *gorm.DB,
+	rdb *redis.Client,
 	userHandler *handlers.UserHandler,
 	boardHandler *handlers.BoardHandler,
 	taskHandler *handlers.TaskHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	auditHandler *handlers.AuditHandler,
+	trashHandler *handlers.TrashHandler,
+	streamHandler *handlers.StreamHandler,
+	webhookHandler *handlers.WebhookHandler,
+	notificationHandler *handlers.NotificationHandler,
 ) {
 
 	// Public endpoints
@@ -19,28 +30,183 @@ func SetupRoutes(
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 	r.POST("/login", userHandler.Login)
+	r.POST("/login/2fa", userHandler.LoginTwoFactor)
 	r.POST("/register", userHandler.CreateUser)
+	r.POST("/token/refresh", userHandler.RefreshToken)
+	r.POST("/password/forgot", userHandler.ForgotPassword)
+	r.POST("/password/reset", userHandler.ResetPassword)
+	r.POST("/email/verify", userHandler.VerifyEmail)
+	r.GET("/auth/oidc/login", userHandler.SSOLogin)
+	r.POST("/auth/oidc/callback", userHandler.SSOCallback)
 
-	// JWT korumalı endpoints
+	// Canlı board event'leri; tarayıcı WebSocket/EventSource header gönderemediği için token query'den de alınır
+	r.GET("/stream", middleware.TokenFromQuery(), middleware.JWTAuthMiddleware(db, rdb), middleware.RequireScope(models.ScopeReadBoards), streamHandler.Stream)
+
+	// JWT ya da kişisel API token'ı ile korunan endpoints.
+	// API token'ları sadece yetkileri (scope) olan gruplara erişebilir; oturum JWT'leri scope kontrolünden etkilenmez.
 	protected := r.Group("/")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(middleware.JWTAuthMiddleware(db, rdb))
 	{
+		protected.GET("/me", userHandler.GetMe)
+
+		// Hesap ve oturum işlemleri sadece oturumla yapılır
+		account := protected.Group("/")
+		account.Use(middleware.RequireSession())
+		{
+			account.POST("/logout", userHandler.Logout)
+			account.POST("/email/verify/resend", userHandler.ResendVerification)
+
+			// Kullanıcının kendi hesabı
+			account.PATCH("/me", userHandler.UpdateMe)
+			account.POST("/me/password", userHandler.ChangePassword)
+			account.DELETE("/me", userHandler.DeleteMe)
+			account.GET("/me/2fa", userHandler.GetTwoFactor)
+			account.POST("/me/2fa/setup", userHandler.SetupTwoFactor)
+			account.POST("/me/2fa/enable", userHandler.EnableTwoFactor)
+			account.POST("/me/2fa/disable", userHandler.DisableTwoFactor)
+			account.POST("/me/2fa/recovery-codes", userHandler.RegenerateRecoveryCodes)
+
+			// Kişisel API token'ları
+			account.GET("/me/tokens", userHandler.GetPersonalTokens)
+			account.POST("/me/tokens", middleware.RequireVerifiedEmail(), userHandler.CreatePersonalToken)
+			account.DELETE("/me/tokens/:id", userHandler.DeletePersonalToken)
+
+			account.POST("/organizations/:id/switch", organizationHandler.SwitchOrganization)
+		}
+
 		// Board endpoints
-		protected.GET("/boards", boardHandler.GetBoards)
-		protected.POST("/boards", boardHandler.CreateBoard)
-		protected.PUT("/boards/:id", boardHandler.UpdateBoard)
-		protected.DELETE("/boards/:id", boardHandler.DeleteBoard)
+		readBoards := protected.Group("/")
+		readBoards.Use(middleware.RequireScope(models.ScopeReadBoards))
+		{
+			readBoards.GET("/boards", boardHandler.GetBoards)
+			readBoards.GET("/boards/:id/members", boardHandler.GetMembers)
+			readBoards.GET("/boards/:id/columns", boardHandler.GetColumns)
+			readBoards.GET("/boards/:id/labels", boardHandler.GetLabels)
+			readBoards.GET("/boards/:id/activity", boardHandler.GetActivity)
+			readBoards.GET("/trash", trashHandler.GetTrash)
+		}
+		protected.DELETE("/boards/:id/members/:userId", middleware.RequireScope(models.ScopeWriteBoards), boardHandler.RemoveMember)
 
 		// Task endpoints
-		protected.GET("/tasks", taskHandler.GetTasks)
-		protected.GET("/tasks/:id", taskHandler.GetTaskByID)
-		protected.POST("/tasks", taskHandler.CreateTask)
-		protected.PUT("/tasks/:id", taskHandler.UpdateTask)
-		protected.DELETE("/tasks/:id", taskHandler.DeleteTask)
-
-		// User endpoints
-		protected.GET("/users", userHandler.GetUsers)
-		protected.PUT("/users/:id", userHandler.UpdateUser)
-		protected.DELETE("/users/:id", userHandler.DeleteUser)
+		readTasks := protected.Group("/")
+		readTasks.Use(middleware.RequireScope(models.ScopeReadTasks))
+		{
+			readTasks.GET("/tasks", taskHandler.GetTasks)
+			readTasks.GET("/tasks/:id", taskHandler.GetTaskByID)
+			readTasks.GET("/tasks/:id/comments", taskHandler.GetComments)
+			readTasks.GET("/tasks/:id/comments/:commentId/revisions", taskHandler.GetCommentRevisions)
+			readTasks.GET("/tasks/:id/attachments", taskHandler.GetAttachments)
+			readTasks.GET("/tasks/:id/attachments/:attachmentId", taskHandler.DownloadAttachment)
+			readTasks.GET("/tasks/:id/checklist", taskHandler.GetChecklist)
+			readTasks.GET("/tasks/:id/subtasks", taskHandler.GetSubtasks)
+			readTasks.GET("/tasks/:id/dependencies", taskHandler.GetDependencies)
+			readTasks.GET("/tasks/:id/history", taskHandler.GetTaskHistory)
+			readTasks.GET("/tasks/:id/watchers", taskHandler.GetWatchers)
+		}
+
+		// Notification endpoints - kullanıcı sadece kendi bildirimlerini görür
+		readNotifications := protected.Group("/")
+		readNotifications.Use(middleware.RequireScope(models.ScopeReadNotifications))
+		{
+			readNotifications.GET("/notifications", notificationHandler.GetNotifications)
+			readNotifications.GET("/notifications/unread-count", notificationHandler.GetUnreadCount)
+			readNotifications.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		}
+		writeNotifications := protected.Group("/")
+		writeNotifications.Use(middleware.RequireScope(models.ScopeWriteNotifications))
+		{
+			writeNotifications.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+			writeNotifications.POST("/notifications/:id/read", notificationHandler.MarkRead)
+			writeNotifications.PUT("/notifications/preferences", notificationHandler.UpdatePreferences)
+			writeNotifications.POST("/tasks/:id/watch", taskHandler.WatchTask)
+			writeNotifications.DELETE("/tasks/:id/watch", taskHandler.UnwatchTask)
+		}
+
+		// Guest kullanıcılar ve e-postasını doğrulamamış hesaplar sadece okuyabilir
+		writers := protected.Group("/")
+		writers.Use(middleware.RequireRole(models.RoleAdmin, models.RoleMember), middleware.RequireVerifiedEmail())
+		{
+			writeBoards := writers.Group("/")
+			writeBoards.Use(middleware.RequireScope(models.ScopeWriteBoards))
+			{
+				writeBoards.POST("/boards", boardHandler.CreateBoard)
+				writeBoards.PUT("/boards/:id", boardHandler.UpdateBoard)
+				writeBoards.DELETE("/boards/:id", boardHandler.DeleteBoard)
+				writeBoards.POST("/boards/:id/members", boardHandler.AddMember)
+				writeBoards.PUT("/boards/:id/members/:userId", boardHandler.UpdateMember)
+				writeBoards.POST("/boards/:id/columns", boardHandler.CreateColumn)
+				writeBoards.PUT("/boards/:id/columns/:columnId", boardHandler.UpdateColumn)
+				writeBoards.DELETE("/boards/:id/columns/:columnId", boardHandler.DeleteColumn)
+				writeBoards.PUT("/boards/:id/transitions", boardHandler.SetTransitions)
+				writeBoards.POST("/boards/:id/labels", boardHandler.CreateLabel)
+				writeBoards.PUT("/boards/:id/labels/:labelId", boardHandler.UpdateLabel)
+				writeBoards.DELETE("/boards/:id/labels/:labelId", boardHandler.DeleteLabel)
+				writeBoards.POST("/trash/:type/:id/restore", trashHandler.Restore)
+			}
+
+			writeTasks := writers.Group("/")
+			writeTasks.Use(middleware.RequireScope(models.ScopeWriteTasks))
+			{
+				writeTasks.POST("/tasks", taskHandler.CreateTask)
+				writeTasks.PUT("/tasks/:id", taskHandler.UpdateTask)
+				writeTasks.DELETE("/tasks/:id", taskHandler.DeleteTask)
+				writeTasks.POST("/tasks/:id/move", taskHandler.MoveTask)
+				writeTasks.POST("/tasks/:id/comments", taskHandler.CreateComment)
+				writeTasks.PUT("/tasks/:id/comments/:commentId", taskHandler.UpdateComment)
+				writeTasks.DELETE("/tasks/:id/comments/:commentId", taskHandler.DeleteComment)
+				writeTasks.POST("/tasks/:id/attachments", taskHandler.UploadAttachment)
+				writeTasks.DELETE("/tasks/:id/attachments/:attachmentId", taskHandler.DeleteAttachment)
+				writeTasks.POST("/tasks/:id/checklist", taskHandler.CreateChecklistItem)
+				writeTasks.PUT("/tasks/:id/checklist/:itemId", taskHandler.UpdateChecklistItem)
+				writeTasks.DELETE("/tasks/:id/checklist/:itemId", taskHandler.DeleteChecklistItem)
+				writeTasks.POST("/tasks/:id/dependencies", taskHandler.AddDependency)
+				writeTasks.DELETE("/tasks/:id/dependencies/:blockerId", taskHandler.RemoveDependency)
+			}
+
+			// Webhook'ları workspace admin'i ya da board owner'ı yönetir
+			webhooks := writers.Group("/")
+			webhooks.Use(middleware.RequireScope(models.ScopeWebhooks))
+			{
+				webhooks.GET("/webhooks", webhookHandler.GetWebhooks)
+				webhooks.POST("/webhooks", webhookHandler.CreateWebhook)
+				webhooks.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+				webhooks.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+				webhooks.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+				webhooks.GET("/webhooks/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+				webhooks.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+			}
+		}
+
+		// Organization (workspace) endpoints
+		readOrganizations := protected.Group("/")
+		readOrganizations.Use(middleware.RequireScope(models.ScopeReadOrganizations))
+		{
+			readOrganizations.GET("/organizations", organizationHandler.GetOrganizations)
+			readOrganizations.GET("/organizations/:id/members", organizationHandler.GetMembers)
+		}
+		writeOrganizations := protected.Group("/")
+		writeOrganizations.Use(middleware.RequireVerifiedEmail(), middleware.RequireScope(models.ScopeWriteOrganizations))
+		{
+			writeOrganizations.POST("/organizations", organizationHandler.CreateOrganization)
+			writeOrganizations.PUT("/organizations/:id", organizationHandler.UpdateOrganization)
+			writeOrganizations.POST("/organizations/:id/members", organizationHandler.AddMember)
+			writeOrganizations.PUT("/organizations/:id/members/:userId", organizationHandler.UpdateMember)
+			writeOrganizations.DELETE("/organizations/:id/members/:userId", organizationHandler.RemoveMember)
+		}
+
+		// Admin endpoints - kullanıcı kendi hesabını /me üzerinden yönetir
+		admin := protected.Group("/")
+		admin.Use(middleware.RequireVerifiedEmail(), middleware.RequireScope(models.ScopeAdmin))
+		{
+			admin.GET("/users", middleware.RequireRole(models.RoleAdmin), userHandler.GetUsers)
+			admin.PUT("/users/:id", middleware.RequireRole(models.RoleAdmin), userHandler.UpdateUser)
+			admin.DELETE("/users/:id", middleware.RequireRole(models.RoleAdmin), userHandler.DeleteUser)
+			admin.POST("/users/:id/sessions/revoke", middleware.RequireSelfOrRole("id", models.RoleAdmin), userHandler.RevokeUserSessions)
+			admin.POST("/users/:id/unlock", middleware.RequireRole(models.RoleAdmin), userHandler.UnlockUser)
+
+			// Audit endpoints
+			admin.GET("/audit", middleware.RequireRole(models.RoleAdmin), auditHandler.GetAuditEvents)
+			admin.GET("/security/events", middleware.RequireRole(models.RoleAdmin), auditHandler.GetSecurityEvents)
+		}
 	}
 }
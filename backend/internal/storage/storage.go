@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+)
+
+// ErrNotFound istenen dosya storage'da yok
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage task eklerinin dosya içeriğini saklayan backend
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Connect STORAGE_DRIVER'a göre backend seçer: "local" (varsayılan) ya da "s3"
+func Connect() Storage {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "s3":
+		s, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+		if err != nil {
+			log.Fatal("S3 storage error:", err)
+		}
+		log.Println("✅ S3 storage connected")
+		return s
+
+	default:
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		s, err := NewLocalStorage(dir)
+		if err != nil {
+			log.Fatal("Local storage error:", err)
+		}
+		log.Println("✅ Local storage ready:", dir)
+		return s
+	}
+}
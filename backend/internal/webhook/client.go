@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+)
+
+var ErrBlockedAddress = errors.New("webhook address is not public")
+
+// Carrier-grade NAT (RFC 6598), netip'in IsPrivate'ına dahil değil
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// allowPrivate lokal geliştirmede (ör. host.docker.internal'daki alıcı) iç adreslere izin verir
+func allowPrivate() bool {
+	return os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true"
+}
+
+// blockedAddr loopback, private, link-local ve diğer genel olmayan adresler
+func blockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsGlobalUnicast() || addr.IsPrivate() || sharedAddressSpace.Contains(addr)
+}
+
+// ValidURL webhook adresini kontrol eder. Host adı burada çözülmez, DNS sonrası kontrol bağlantı anında yapılır.
+func ValidURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || len(raw) > 500 {
+		return errors.New("invalid webhook url")
+	}
+	if allowPrivate() {
+		return nil
+	}
+	if u.Hostname() == "localhost" {
+		return ErrBlockedAddress
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && blockedAddr(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// dialControl bağlanılacak IP'yi DNS çözümlemesinden sonra kontrol eder; DNS rebinding ile iç ağa erişilemez
+func dialControl(network, address string, _ syscall.RawConn) error {
+	if allowPrivate() {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if blockedAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// NewClient webhook teslimatları için HTTP client; iç adreslere bağlanmaz, proxy kullanmaz ve redirect izlemez
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// 3xx cevabı başarısız teslimat sayılır, yönlendirilen adres takip edilmez
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
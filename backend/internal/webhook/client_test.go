@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestBlockedAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		blocked bool
+	}{
+		{"8.8.8.8", false},
+		{"1.1.1.1", false},
+		{"2606:4700:4700::1111", false},
+		{"127.0.0.1", true},
+		{"127.8.8.8", true},
+		{"::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"10.0.0.1", true},
+		{"172.16.0.1", true},
+		{"172.31.255.255", true},
+		{"172.32.0.1", false},
+		{"192.168.1.1", true},
+		{"fc00::1", true},
+		{"fd12:3456::1", true},
+		// Link-local: cloud metadata servisi
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		// Carrier-grade NAT 100.64.0.0/10
+		{"100.64.0.1", true},
+		{"100.127.255.255", true},
+		{"100.63.255.255", false},
+		{"100.128.0.1", false},
+		{"224.0.0.1", true},
+		{"255.255.255.255", true},
+		// IPv4-mapped IPv6 adresleri IPv4 karşılıklarıyla aynı sonucu verir
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"::ffff:100.64.0.1", true},
+		{"::ffff:8.8.8.8", false},
+	}
+	for _, tt := range tests {
+		if got := blockedAddr(netip.MustParseAddr(tt.addr)); got != tt.blocked {
+			t.Errorf("blockedAddr(%s) = %v, want %v", tt.addr, got, tt.blocked)
+		}
+	}
+}
+
+func TestValidURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		blocked bool
+		invalid bool
+	}{
+		{url: "https://example.com/hook"},
+		{url: "http://8.8.8.8:8080/hook"},
+		{url: "http://localhost:8080/hook", blocked: true},
+		{url: "http://127.0.0.1/hook", blocked: true},
+		{url: "http://[::1]/hook", blocked: true},
+		{url: "http://[::ffff:169.254.169.254]/latest", blocked: true},
+		{url: "http://100.100.100.200/hook", blocked: true},
+		{url: "ftp://example.com/hook", invalid: true},
+		{url: "https:///hook", invalid: true},
+		{url: "not a url", invalid: true},
+	}
+	for _, tt := range tests {
+		err := ValidURL(tt.url)
+		switch {
+		case tt.blocked:
+			if !errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("ValidURL(%q) = %v, want ErrBlockedAddress", tt.url, err)
+			}
+		case tt.invalid:
+			if err == nil || errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("ValidURL(%q) = %v, want invalid url", tt.url, err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("ValidURL(%q) = %v", tt.url, err)
+			}
+		}
+	}
+
+	// Lokal geliştirmede iç adreslere izin verilebilir
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	if err := ValidURL("http://localhost:8080/hook"); err != nil {
+		t.Errorf("WEBHOOK_ALLOW_PRIVATE: ValidURL = %v", err)
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	// DNS çözümlemesinden sonraki adres de aynı kurala tabi
+	if err := dialControl("tcp4", "10.1.2.3:443", nil); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("private address: err = %v", err)
+	}
+	if err := dialControl("tcp6", "[::ffff:127.0.0.1]:80", nil); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("mapped loopback: err = %v", err)
+	}
+	if err := dialControl("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public address: err = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahmetcanc/TaskMan/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Bu kadar başarısız denemeden sonra teslimat failed olur
+	MaxAttempts = 8
+	// İlk yeniden deneme gecikmesi, her denemede iki katına çıkar
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+	batchSize      = 20
+	// Tek bir teslimat isteğinin süresi
+	sendTimeout = 10 * time.Second
+	// Alınan teslimat bu süre içinde bitmezse başka bir instance tekrar dener. Grup sırayla
+	// gönderildiği için en kötü durumda (her istek timeout) grubun bitmesinden uzun olmalı.
+	claimLease = batchSize*sendTimeout + time.Minute
+	// Log'da saklanan response body sınırı
+	maxResponseBody = 2048
+)
+
+// RetryDelay attempts'inci başarısız denemeden sonra beklenecek süre: 30s, 1m, 2m, 4m... en fazla 6 saat
+func RetryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// Dispatcher bekleyen teslimatları gönderir, birden fazla instance'ta güvenle çalışır
+type Dispatcher struct {
+	DB     *gorm.DB
+	Client *http.Client
+}
+
+func NewDispatcher(db *gorm.DB) *Dispatcher {
+	return &Dispatcher{
+		DB:     db,
+		Client: NewClient(sendTimeout),
+	}
+}
+
+// Run teslimatları interval aralıklarla işler, main'de goroutine olarak çalıştırılır
+func (d *Dispatcher) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			n, err := d.DispatchDue()
+			if err != nil {
+				log.Println("webhook dispatch error:", err)
+			}
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+	}
+}
+
+// DispatchDue zamanı gelmiş teslimatları gönderir ve işlenen sayısını döner
+func (d *Dispatcher) DispatchDue() (int, error) {
+	deliveries, err := d.claim()
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookID)
+	}
+	var webhooks []models.Webhook
+	if err := d.DB.Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return 0, err
+	}
+	byID := map[uint]models.Webhook{}
+	for _, w := range webhooks {
+		byID[w.ID] = w
+	}
+
+	for _, delivery := range deliveries {
+		w, ok := byID[delivery.WebhookID]
+		if !ok || !w.Active {
+			d.finish(&delivery, models.DeliveryFailed, "webhook disabled or deleted")
+			continue
+		}
+		d.send(w, &delivery)
+	}
+	return len(deliveries), nil
+}
+
+// claim zamanı gelmiş teslimatları kilitleyip lease süresi kadar ileri atar
+func (d *Dispatcher) claim() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at, id").Limit(batchSize).Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(claimLease)).Error
+	})
+	return deliveries, err
+}
+
+func (d *Dispatcher) send(w models.Webhook, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		d.finish(delivery, models.DeliveryFailed, err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskMan-Webhook/1.0")
+	req.Header.Set("X-TaskMan-Event", delivery.EventType)
+	req.Header.Set("X-TaskMan-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-TaskMan-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-TaskMan-Signature", Sign(w.Secret, now, delivery.Payload))
+
+	delivery.Attempts++
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		delivery.ResponseCode = 0
+		delivery.ResponseBody = ""
+		d.retry(delivery, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	delivery.ResponseCode = resp.StatusCode
+	delivery.ResponseBody = string(body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.DeliveredAt = &now
+		d.finish(delivery, models.DeliverySuccess, "")
+		return
+	}
+	d.retry(delivery, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+}
+
+// retry deneme hakkı kaldıysa bir sonraki denemeyi planlar
+func (d *Dispatcher) retry(delivery *models.WebhookDelivery, reason string) {
+	if delivery.Attempts >= MaxAttempts {
+		d.finish(delivery, models.DeliveryFailed, reason)
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(RetryDelay(delivery.Attempts))
+	d.finish(delivery, models.DeliveryPending, reason)
+}
+
+func (d *Dispatcher) finish(delivery *models.WebhookDelivery, status, reason string) {
+	delivery.Status = status
+	delivery.Error = reason
+	if err := d.DB.Save(delivery).Error; err != nil {
+		log.Println("webhook delivery save error:", err)
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook'ların abone olabileceği entity'ler ve işlemler, event tipi "<entity>.<action>"
+var (
+	entities = []string{"board", "task", "board_member", "column", "label", "comment", "attachment", "checklist_item", "dependency"}
+	actions  = []string{"created", "updated", "deleted", "restored"}
+)
+
+// Task status'ü değiştiğinde task.updated'a ek olarak gönderilir
+const TaskStatusChanged = "task.status_changed"
+
+// Tüm event'lere abonelik
+const AllEvents = "*"
+
+// ValidEvent event tipinin tanımlı olup olmadığını kontrol eder
+func ValidEvent(event string) bool {
+	if event == AllEvents || event == TaskStatusChanged {
+		return true
+	}
+	entity, action, ok := strings.Cut(event, ".")
+	if !ok {
+		return false
+	}
+	return contains(entities, entity) && contains(actions, action)
+}
+
+// Subscribed virgülle ayrılmış event listesi event'i içeriyorsa true döner
+func Subscribed(events, event string) bool {
+	for _, e := range strings.Split(events, ",") {
+		if e == AllEvents || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign "<timestamp>.<body>" için HMAC-SHA256 imzası, X-TaskMan-Signature header'ında "sha256=<hex>" olarak gönderilir.
+// Alıcı aynı hesabı yapıp hmac.Equal ile karşılaştırmalı ve eski timestamp'leri reddetmeli.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
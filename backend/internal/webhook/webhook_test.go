@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	// Beklenen değerler HMAC-SHA256(secret, "<timestamp>.<body>") ile bağımsız hesaplandı
+	tests := []struct {
+		secret string
+		body   string
+		want   string
+	}{
+		{"whsec_test", `{"type":"task.created"}`, "sha256=b14203adcb404d4b52c0646691229b66260532ee315b2e423b43441754f0587d"},
+		{"whsec_test", "", "sha256=5967f3c560522fa40cf2876ebc3c3a08551dd6959aaade3b413460591895bdcc"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, ts, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %q) = %s, want %s", tt.secret, tt.body, got, tt.want)
+		}
+	}
+
+	// Secret, timestamp ya da body değişirse imza da değişir
+	base := Sign("whsec_test", ts, []byte("{}"))
+	for name, got := range map[string]string{
+		"secret":    Sign("whsec_other", ts, []byte("{}")),
+		"timestamp": Sign("whsec_test", ts.Add(time.Second), []byte("{}")),
+		"body":      Sign("whsec_test", ts, []byte("{ }")),
+	} {
+		if got == base {
+			t.Errorf("signature unchanged when %s changes", name)
+		}
+	}
+}
@@ -1,25 +1,43 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/ahmetcanc/TaskMan/internal/cache"
 	"github.com/ahmetcanc/TaskMan/internal/db"
 	"github.com/ahmetcanc/TaskMan/internal/handlers"
+	"github.com/ahmetcanc/TaskMan/internal/mail"
+	"github.com/ahmetcanc/TaskMan/internal/middleware"
+	"github.com/ahmetcanc/TaskMan/internal/oidc"
+	"github.com/ahmetcanc/TaskMan/internal/outbox"
 	"github.com/ahmetcanc/TaskMan/internal/routes"
+	"github.com/ahmetcanc/TaskMan/internal/storage"
+	"github.com/ahmetcanc/TaskMan/internal/webhook"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	r := gin.Default()
+	// gin.Default ile aynı, log'da access token gizlenir
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
+	r.Use(middleware.RequestID())
+
+	// X-Forwarded-For sadece güvenilen proxy'lerden kabul edilir, yoksa giriş denemesi limiti IP taklidiyle aşılabilir
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatal("❌ invalid TRUSTED_PROXIES:", err)
+	}
 
 	// CORS middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"}, // Frontend portun
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -27,17 +45,53 @@ func main() {
 	// DB & Redis bağlantısı
 	database := db.Connect()
 	rdb := cache.RedisConnect()
+	store := storage.Connect()
+	mailer := mail.Connect()
+	provider := oidc.Connect()
 
 	// Örnek veri
 	db.ExamData(database)
 
 	// Handler’lar
-	boardHandler := handlers.NewBoardHandler(database, rdb)
-	taskHandler := handlers.NewTaskHandler(database, rdb)
-	userHandler := handlers.NewUserHandler(database, rdb)
+	boardHandler := handlers.NewBoardHandler(database, rdb, store)
+	taskHandler := handlers.NewTaskHandler(database, rdb, store)
+	userHandler := handlers.NewUserHandler(database, rdb, store, mailer, provider)
+	organizationHandler := handlers.NewOrganizationHandler(database, rdb)
+	auditHandler := handlers.NewAuditHandler(database, rdb)
+	trashHandler := handlers.NewTrashHandler(database, rdb, store)
+	streamHandler := handlers.NewStreamHandler(database, rdb)
+	webhookHandler := handlers.NewWebhookHandler(database, rdb)
+	notificationHandler := handlers.NewNotificationHandler(database, rdb)
+
+	// Outbox'taki event'leri realtime, webhook ve cache consumer'larına ilet
+	go outbox.NewRelay(database, handlers.OutboxConsumers(database, rdb)...).Run(500 * time.Millisecond)
+
+	// Bildirim e-postaları ve günlük özetler
+	go mail.NewWorker(database, mailer).Run(30 * time.Second)
+	go taskHandler.RunDueReminders(15 * time.Minute)
+
+	// Bekleyen webhook teslimatlarını gönder
+	go webhook.NewDispatcher(database).Run(5 * time.Second)
+
+	// Redis'teki board event'lerini bu instance'a bağlı client'lara dağıt
+	go streamHandler.Hub.Run(context.Background())
+
+	// Süresi dolan çöp kutusu kayıtlarını saatlik temizle
+	go trashHandler.RunPurge(time.Hour)
 
 	// Routes
-	routes.SetupRoutes(r, userHandler, boardHandler, taskHandler)
+	routes.SetupRoutes(r, database, rdb, userHandler, boardHandler, taskHandler, organizationHandler, auditHandler, trashHandler, streamHandler, webhookHandler, notificationHandler)
 
 	r.Run(":8080")
 }
+
+// trustedProxies TRUSTED_PROXIES'teki virgülle ayrılmış IP/CIDR listesi; boşsa hiçbir proxy'ye güvenilmez
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}